@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"testme/parser"
+)
+
+// batchLog writes one structured record per processed file when -log json
+// is given; otherwise it is nil and records are dropped.
+var batchLog *slog.Logger
+
+// setLogFormat selects the record format for the headless modes.
+func setLogFormat(format string) error {
+	switch format {
+	case "":
+	case "json":
+		batchLog = slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	default:
+		return fmt.Errorf("unknown log format %q, want json", format)
+	}
+	return nil
+}
+
+// logFile records the outcome of processing path, which was parsed into c
+// (when err is nil) starting at start.
+func logFile(path string, c parser.Census, start time.Time, err error) {
+	if batchLog == nil {
+		return
+	}
+	attrs := []any{
+		slog.String("path", path),
+		slog.Bool("ok", err == nil),
+		slog.Int64("ms", time.Since(start).Milliseconds()),
+	}
+	if err != nil {
+		batchLog.Error("file", append(attrs, slog.String("error", err.Error()))...)
+		return
+	}
+	attrs = append(attrs, slog.Int("rows", filledRows(c)), slog.Any("warnings", warnings(c)))
+	batchLog.Info("file", attrs...)
+}
+
+// filledRows counts the body rows of c with any value in them.
+func filledRows(c parser.Census) int {
+	rows := 0
+	for _, r := range c.Rows {
+		if r.Col != ([parser.FieldCount]string{}) {
+			rows++
+		}
+	}
+	return rows
+}
+
+// warnings lists the things about a parsed page worth a second look.
+func warnings(c parser.Census) []string {
+	w := []string{}
+	matched := 0
+	for _, ok := range c.HeaderMatched {
+		if ok {
+			matched++
+		}
+	}
+	if matched < parser.HeadCount {
+		w = append(w, fmt.Sprintf("%d of %d header fields matched by label", matched, parser.HeadCount))
+	}
+	if c.Year == "" {
+		w = append(w, "no census year in file")
+	}
+	return append(w, c.Notes...)
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Config holds the user preferences kept between sessions.
+type Config struct {
+	AutoSchedule   bool `json:"autoSchedule"`   // suggest the next schedule number in an empty Sched# cell
+	FillDown       bool `json:"fillDown"`       // carry the address down into a blank row
+	NormalizeSpace bool `json:"normalizeSpace"` // trim and collapse whitespace when committing a cell
+	CellIDs        bool `json:"cellIDs"`        // write id anchors on the footer totals
+	CloneFooter    bool `json:"cloneFooter"`    // keep the footer when cloning a page
+	VerifyWrites   bool `json:"verifyWrites"`   // read saved HTML back and compare it with the sheet
+	Separators     bool `json:"separators"`     // rule off households in HTML output
+	Canonical      bool `json:"canonical"`      // one body cell per line in HTML output
+	PlaceLinks     bool `json:"placeLinks"`     // link birthplaces to a places index
+	Relationships  bool `json:"relationships"`  // link spouses and children to heads in the households export
+	Strict         bool `json:"strict"`         // refuse to write a sheet with errors
+
+	// OverwriteTotals lets computed footer totals replace values already
+	// typed in without asking first.
+	OverwriteTotals bool `json:"overwriteTotals,omitempty"`
+
+	// AutoTotals keeps the footer totals in step with the body: they are
+	// recomputed on entering the footer and before writing, except in cells
+	// typed by hand, until those are cleared.
+	AutoTotals bool `json:"autoTotals,omitempty"`
+
+	// SexTotals is what the male and female totals add up: "count" (or "")
+	// counts the rows with an age in each column, "ages" sums the ages.
+	SexTotals string `json:"sexTotals,omitempty"`
+
+	// EnterAction is what Enter does while editing: "field" (or "") advances
+	// like Tab, "row" starts the next body row, "none" leaves it to the input.
+	EnterAction string `json:"enterAction,omitempty"`
+
+	// Thousands groups the digits of computed footer totals: "comma" writes
+	// "1,234", "space" "1 234", and "none" (or "") writes them plainly.
+	// Typed totals are compared by number, so "1,234" matches 1234 whatever
+	// is set.
+	Thousands string `json:"thousands,omitempty"`
+
+	// MaxCellWidth shortens long body cells in HTML output to this many
+	// characters, with the full text as a tooltip. Zero leaves cells whole.
+	MaxCellWidth int `json:"maxCellWidth,omitempty"`
+
+	// TextWidth is the column width of the fixed-width text listing. Zero
+	// uses the default.
+	TextWidth int `json:"textWidth,omitempty"`
+
+	// Theme names the built-in stylesheet of saved HTML: "plain" (or "")
+	// or "period".
+	Theme string `json:"theme,omitempty"`
+
+	// RowCount writes the HTML table with exactly this many body rows, for
+	// forms with a fixed number of lines. Zero writes the sheet's rows.
+	RowCount int `json:"rowCount,omitempty"`
+
+	// Autosave is how many seconds pass between backups of the sheet to
+	// census.autosave.html; zero means DefaultAutosave and a negative value
+	// turns backups off.
+	Autosave int `json:"autosave,omitempty"`
+
+	// Credit is printed below the table in HTML output, e.g. a transcriber
+	// and copyright line.
+	Credit string `json:"credit,omitempty"`
+
+	// Years lists the census years offered on start; empty means
+	// DefaultYears. YearIndex picks the one highlighted, counting from 0.
+	Years     []string `json:"years,omitempty"`
+	YearIndex *int     `json:"yearIndex,omitempty"`
+
+	// Recent lists the files last opened or written, most recent first.
+	Recent []string `json:"recent,omitempty"`
+
+	// GridKeys names the key set moving around the body grid: "" for
+	// Alt+arrows, "vi" for Alt+h/j/k/l.
+	GridKeys string `json:"gridKeys,omitempty"`
+}
+
+// DefaultAutosave is the interval between backups when Autosave is zero.
+const DefaultAutosave = 60 * time.Second
+
+// AutosaveEvery returns the interval between backups, or zero when they are
+// turned off.
+func (c Config) AutosaveEvery() time.Duration {
+	switch {
+	case c.Autosave < 0:
+		return 0
+	case c.Autosave == 0:
+		return DefaultAutosave
+	}
+	return time.Duration(c.Autosave) * time.Second
+}
+
+// DefaultYears are the UK census years, highlighting DefaultYearIndex (1861).
+var DefaultYears = []string{"1841", "1851", "1861", "1871", "1881", "1891", "1901", "1911", "1921"}
+
+const DefaultYearIndex = 2
+
+// YearMenu returns the years offered on start and the index of the one to
+// highlight. A custom list without an index highlights its first year. An
+// index outside the list is an error, and the first year is used instead.
+func (c Config) YearMenu() ([]string, int, error) {
+	years, idx := DefaultYears, DefaultYearIndex
+	if len(c.Years) > 0 {
+		years, idx = c.Years, 0
+	}
+	if c.YearIndex != nil {
+		idx = *c.YearIndex
+	}
+	if idx < 0 || idx >= len(years) {
+		return years, 0, fmt.Errorf("yearIndex %d is outside the %d years listed", idx, len(years))
+	}
+	return years, idx, nil
+}
+
+// MaxRecent is how many files Recent remembers.
+const MaxRecent = 8
+
+// AddRecent puts path at the front of Recent, dropping an earlier entry for
+// it and the oldest beyond MaxRecent.
+func (c *Config) AddRecent(path string) {
+	recent := []string{path}
+	for _, p := range c.Recent {
+		if p != path && len(recent) < MaxRecent {
+			recent = append(recent, p)
+		}
+	}
+	c.Recent = recent
+}
+
+// Path returns the location of the config file.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "census-tui", "config.json"), nil
+}
+
+// Load reads the config file. A missing file yields the zero Config.
+func Load() (Config, error) {
+	var c Config
+	path, err := Path()
+	if err != nil {
+		return c, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(data, &c)
+	return c, err
+}
+
+// Save writes c to the config file, creating its directory if needed.
+func Save(c Config) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Keymap binds editor actions to keys, written the way Bubble Tea names
+// them: "ctrl+w", "alt+p", "esc".
+type Keymap map[string]string
+
+// DefaultKeymap is the binding of every action when the keymap file does
+// not change it.
+var DefaultKeymap = Keymap{
+	"quit":          "esc",
+	"settings":      "ctrl+t",
+	"header":        "ctrl+h",
+	"body":          "ctrl+b",
+	"footer":        "ctrl+f",
+	"next-area":     "ctrl+@",
+	"open":          "ctrl+o",
+	"write":         "ctrl+w",
+	"export-all":    "ctrl+x",
+	"export-csv":    "ctrl+e",
+	"save-json":     "ctrl+j",
+	"export-txt":    "ctrl+p",
+	"clear":         "ctrl+n",
+	"undo":          "ctrl+z",
+	"redo":          "ctrl+y",
+	"ref":           "ctrl+r",
+	"paste":         "alt+v",
+	"replace":       "alt+r",
+	"preview":       "alt+p",
+	"restore-row":   "alt+n",
+	"stats":         "alt+s",
+	"totals":        "alt+t",
+	"clone-page":    "alt+c",
+	"same-address":  "alt+a",
+	"ditto":         "alt+d",
+	"cycle-value":   "alt+ ",
+	"insert-row":    "insert",
+	"delete-row":    "ctrl+d",
+	"copy-row":      "alt+y",
+	"paste-row":     "alt+b",
+	"paste-address": "alt+B",
+	"markup":        "alt+i",
+	"swap-name":     "alt+x",
+	"uncertain":     "alt+u",
+	"merge-places":  "alt+g",
+	"list":          "ctrl+l",
+	"overview":      "alt+o",
+	"by-column":     "alt+m",
+	"calculator":    "alt+e",
+	"review":        "alt+q",
+	"goto-row":      "ctrl+g",
+	"search":        "ctrl+_",
+	"next-match":    "f3",
+	"next-page":     "ctrl+pgdown",
+	"prev-page":     "ctrl+pgup",
+}
+
+// reservedKeys move around the form and cannot be bound to actions.
+var reservedKeys = []string{"tab", "shift+tab", "enter", "up", "down", "ctrl+c"}
+
+// KeymapPath returns the location of the keymap file.
+func KeymapPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "census-tui", "keymap.json"), nil
+}
+
+// LoadKeymap reads the keymap file over the defaults. A missing file yields
+// DefaultKeymap. Unknown actions and keys bound twice are errors, in which
+// case the defaults are returned with the error.
+func LoadKeymap() (Keymap, error) {
+	km := Keymap{}
+	for a, k := range DefaultKeymap {
+		km[a] = k
+	}
+	path, err := KeymapPath()
+	if err != nil {
+		return DefaultKeymap, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return km, nil
+	}
+	if err != nil {
+		return DefaultKeymap, err
+	}
+	var file Keymap
+	if err := json.Unmarshal(data, &file); err != nil {
+		return DefaultKeymap, fmt.Errorf("%s: %w", path, err)
+	}
+	for a, k := range file {
+		if _, ok := DefaultKeymap[a]; !ok {
+			return DefaultKeymap, fmt.Errorf("%s: unknown action %q", path, a)
+		}
+		km[a] = k
+	}
+	if err := km.check(); err != nil {
+		return DefaultKeymap, fmt.Errorf("%s: %w", path, err)
+	}
+	return km, nil
+}
+
+// check reports a key bound to two actions or to a reserved key.
+func (km Keymap) check() error {
+	owner := map[string]string{}
+	for _, k := range reservedKeys {
+		owner[k] = "navigation"
+	}
+	for _, a := range km.Actions() {
+		k := km[a]
+		if prev, ok := owner[k]; ok {
+			return fmt.Errorf("%q is bound to both %s and %s", k, prev, a)
+		}
+		owner[k] = a
+	}
+	return nil
+}
+
+// Actions returns the bound action names in sorted order.
+func (km Keymap) Actions() []string {
+	names := make([]string, 0, len(km))
+	for a := range km {
+		names = append(names, a)
+	}
+	sort.Strings(names)
+	return names
+}
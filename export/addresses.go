@@ -0,0 +1,44 @@
+package export
+
+import (
+	"os"
+	"strings"
+
+	"testme/parser"
+	"testme/schema"
+)
+
+// WriteAddresses writes each distinct Road / House value of the body, one per
+// line in the order first written, as the seed of a street index.
+func WriteAddresses(c parser.Census, filename string, opts Options) error {
+	rows := parser.RowRange(c.Rows, opts.From, opts.To)
+	var b strings.Builder
+	for _, a := range uniqueAddresses(rows) {
+		b.WriteString(a + "\n")
+	}
+	return os.WriteFile(filename, []byte(b.String()), 0o644)
+}
+
+// uniqueAddresses returns the non-empty addresses of rows without repeats,
+// in first-seen order. Surrounding spaces are ignored, and a ditto mark is
+// read as the address above it.
+func uniqueAddresses(rows []parser.Row) []string {
+	var out []string
+	seen := map[string]bool{}
+	last := ""
+	for _, r := range rows {
+		a := strings.TrimSpace(r.Col[schema.Address])
+		if parser.IsDitto(a) && last != "" {
+			a = last
+		}
+		if a != "" {
+			last = a
+		}
+		if a == "" || seen[a] {
+			continue
+		}
+		seen[a] = true
+		out = append(out, a)
+	}
+	return out
+}
@@ -0,0 +1,32 @@
+package export
+
+import (
+	"slices"
+	"testing"
+
+	"testme/parser"
+	"testme/schema"
+)
+
+func TestUniqueAddresses(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   []string
+		want []string
+	}{
+		{"first-seen order", []string{"Low Street", "High Street", "Church Lane"}, []string{"Low Street", "High Street", "Church Lane"}},
+		{"repeats", []string{"High Street", " High Street ", "Low Street", "High Street"}, []string{"High Street", "Low Street"}},
+		{"blanks", []string{"", "High Street", "", "  "}, []string{"High Street"}},
+		{"ditto", []string{"High Street", "do", "Do.", `"`, "Low Street", "ditto"}, []string{"High Street", "Low Street"}},
+		{"ditto after a blank", []string{"High Street", "", "do"}, []string{"High Street"}},
+		{"ditto with nothing above", []string{"do", "High Street"}, []string{"do", "High Street"}},
+	} {
+		rows := make([]parser.Row, len(tc.in))
+		for i, a := range tc.in {
+			rows[i].Col[schema.Address] = a
+		}
+		if got := uniqueAddresses(rows); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package export
+
+import (
+	"encoding/csv"
+	"os"
+
+	"testme/parser"
+	"testme/schema"
+	tpl "testme/template"
+)
+
+// WriteCSV writes the page for a spreadsheet: the census year, the header,
+// page details and footer totals as label, value lines, a blank line, then
+// the body under a line of column labels, one line per row in form order.
+// Blank rows after the last filled one are left out.
+func WriteCSV(c parser.Census, filename string, opts Options) error {
+	rows := parser.RowRange(c.Rows, opts.From, opts.To)
+	for len(rows) > 0 && rows[len(rows)-1].Col == ([parser.FieldCount]string{}) {
+		rows = rows[:len(rows)-1]
+	}
+	sc := schema.For(c.Year)
+
+	records := [][]string{{"Census year", c.Year}}
+	for i, l := range tpl.HeaderLabels {
+		records = append(records, []string{l, c.Header[i]})
+	}
+	for i, v := range c.Meta.Fields() {
+		records = append(records, []string{parser.MetaLabels[i], *v})
+	}
+	for i, l := range tpl.DLFooterLabels {
+		records = append(records, []string{l, c.Footer[i]})
+	}
+	records = append(records, []string{})
+
+	labels := make([]string, len(sc.Columns))
+	for i, col := range sc.Columns {
+		labels[i] = sc.Labels[col]
+	}
+	records = append(records, labels)
+	for _, r := range rows {
+		cells := make([]string, len(sc.Columns))
+		for i, col := range sc.Columns {
+			cells[i] = r.Col[col]
+		}
+		records = append(records, cells)
+	}
+
+	out, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(out)
+	if err := w.WriteAll(records); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,132 @@
+package export
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"testme/parser"
+	tpl "testme/template"
+)
+
+// Options carries format-specific settings to the exporters.
+type Options struct {
+	HTML tpl.Options
+
+	// PlaceLinks links each birthplace in the HTML to the places index
+	// written beside it.
+	PlaceLinks bool
+
+	// Relationships links each household's spouse and children to its head
+	// in the households export.
+	Relationships bool
+
+	// TextWidth is the width of each column of the txt listing, in terminal
+	// cells; zero means DefaultTextWidth.
+	TextWidth int
+
+	// From and To limit output to body rows From..To (1-based, inclusive).
+	// Zero values mean every row.
+	From, To int
+}
+
+// ParseRange parses a row range such as "5-10" or "7" into 1-based bounds,
+// rejecting ranges that start before row 1 or run backwards. Rows past the
+// end of a sheet are left out when it is written.
+func ParseRange(s string) (from, to int, err error) {
+	lo, hi, found := strings.Cut(s, "-")
+	if !found {
+		hi = lo
+	}
+	from, err1 := strconv.Atoi(strings.TrimSpace(lo))
+	to, err2 := strconv.Atoi(strings.TrimSpace(hi))
+	if err1 != nil || err2 != nil {
+		return 0, 0, fmt.Errorf("invalid row range %q, want N or N-M", s)
+	}
+	if from < 1 || from > to {
+		return 0, 0, fmt.Errorf("row range %d-%d does not run forward from row 1 or later", from, to)
+	}
+	return from, to, nil
+}
+
+// Format is one output format a census page can be written in.
+type Format struct {
+	Name  string // short name used on the command line, e.g. "html"
+	Ext   string // file extension including the dot
+	Write func(c parser.Census, filename string, opts Options) error
+}
+
+// formats lists every known exporter in the order WriteAll runs them.
+var formats = []Format{
+	{Name: "html", Ext: ".html", Write: func(c parser.Census, filename string, opts Options) error {
+		opts.HTML.From, opts.HTML.To = opts.From, opts.To
+		if opts.PlaceLinks {
+			opts.HTML.PlaceIndex = filepath.Base(strings.TrimSuffix(filename, ".html") + ".places.html")
+		}
+		return tpl.WriteHTML(c, filename, opts.HTML)
+	}},
+	{Name: "dl", Ext: ".dl.html", Write: func(c parser.Census, filename string, opts Options) error {
+		opts.HTML.From, opts.HTML.To = opts.From, opts.To
+		return tpl.WriteDL(c, filename, opts.HTML)
+	}},
+	{Name: "refs", Ext: ".refs.json", Write: WriteRefs},
+	{Name: "households", Ext: ".households.json", Write: WriteHouseholdsJSON},
+	{Name: "addresses", Ext: ".addresses.txt", Write: WriteAddresses},
+	{Name: "csv", Ext: ".csv", Write: WriteCSV},
+	{Name: "txt", Ext: ".txt", Write: WriteTXT},
+	{Name: "json", Ext: ".json", Write: WriteJSON},
+	{Name: "gedcom", Ext: ".ged", Write: WriteGEDCOM},
+	{Name: "places", Ext: ".places.html", Write: func(c parser.Census, filename string, opts Options) error {
+		opts.HTML.From, opts.HTML.To = opts.From, opts.To
+		page := filepath.Base(strings.TrimSuffix(filename, ".places.html") + ".html")
+		return tpl.WritePlaces(c, filename, page, opts.HTML)
+	}},
+}
+
+// Formats returns the names of all registered formats.
+func Formats() []string {
+	names := make([]string, len(formats))
+	for i, f := range formats {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// Result records the outcome of writing a single format.
+type Result struct {
+	Format string
+	Path   string
+	Err    error
+}
+
+func (r Result) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("✗ %s: %v", r.Path, r.Err)
+	}
+	return "✓ " + r.Path
+}
+
+// WriteAll writes c to base plus each format's extension. Only the named
+// formats are written, or every format when names is empty. A failing format
+// does not stop the others; an unknown name is reported as a failed result.
+func WriteAll(c parser.Census, base string, names []string, opts Options) []Result {
+	want := map[string]bool{}
+	for _, n := range names {
+		want[strings.ToLower(strings.TrimSpace(n))] = true
+	}
+
+	var res []Result
+	for _, f := range formats {
+		if len(names) > 0 && !want[f.Name] {
+			continue
+		}
+		delete(want, f.Name)
+		path := base + f.Ext
+		res = append(res, Result{Format: f.Name, Path: path, Err: f.Write(c, path, opts)})
+	}
+	for n := range want {
+		res = append(res, Result{Format: n, Path: base, Err: fmt.Errorf("unknown format %q", n)})
+	}
+	return res
+}
@@ -0,0 +1,180 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"testme/parser"
+	"testme/schema"
+)
+
+// WriteGEDCOM writes the people of the body as a GEDCOM 5.5.1 file for
+// family-tree programs. Every row with a name becomes an INDI record:
+//
+//	Name & Surname  NAME, the last word taken as the surname, or the one
+//	                before for a ditto
+//	Age of males    SEX M, and BIRT DATE ABT the census year less the age
+//	Age of females  SEX F, likewise; SEX U when neither age is filled
+//	Where born      BIRT PLAC, except in 1841, where it is only Y or N
+//	Occupation      OCCU
+//	Road / House    CENS PLAC, with the parish after it, and the census
+//	                year as CENS DATE
+//	Relation        CENS NOTE, as the relationship to the head
+//
+// The people of each household, as parser.HouseholdStarts groups them by
+// schedule number, make a FAM when parser.InferFamily links the head to a
+// wife or husband or to sons and daughters: the head and spouse are HUSB and
+// WIFE by their sex, and the children CHIL. Others in the household, such as
+// servants and lodgers, are left out of it.
+func WriteGEDCOM(c parser.Census, filename string, opts Options) error {
+	rows, first := c.Rows[:], 0
+	if opts.From > 0 {
+		rows, first = parser.RowRange(rows, opts.From, opts.To), opts.From-1
+	}
+	sc := schema.For(c.Year)
+
+	var b strings.Builder
+	line := func(level int, text string) { fmt.Fprintf(&b, "%d %s\n", level, text) }
+	value := func(level int, tag, v string) {
+		if v = strings.ReplaceAll(strings.Join(strings.Fields(v), " "), "@", "@@"); v != "" {
+			fmt.Fprintf(&b, "%d %s %s\n", level, tag, v)
+		}
+	}
+	indi := func(i int) string { return "@I" + strconv.Itoa(first+i+1) + "@" }
+	fam := func(i int) string { return "@F" + strconv.Itoa(i+1) + "@" }
+
+	// households and their families first, so the INDI records can point
+	// to them
+	var fams []parser.Family
+	links := map[int][]string{} // FAMS and FAMC lines, by row
+	var members []int
+	flush := func() {
+		if len(members) == 0 {
+			return
+		}
+		f := parser.InferFamily(rows, members)
+		members = nil
+		if f.Head < 0 || (f.Spouse < 0 && len(f.Children) == 0) {
+			return
+		}
+		id := fam(len(fams))
+		fams = append(fams, f)
+		links[f.Head] = append(links[f.Head], "FAMS "+id)
+		if f.Spouse >= 0 {
+			links[f.Spouse] = append(links[f.Spouse], "FAMS "+id)
+		}
+		for _, ch := range f.Children {
+			links[ch] = append(links[ch], "FAMC "+id)
+		}
+	}
+	for i, start := range parser.HouseholdStarts(rows) {
+		if start {
+			flush()
+		}
+		if strings.TrimSpace(rows[i].Col[schema.Name]) != "" {
+			members = append(members, i)
+		}
+	}
+	flush()
+
+	line(0, "HEAD")
+	line(1, "SOUR CENSUS_TUI")
+	line(2, "NAME Census TUI")
+	line(1, "SUBM @U1@")
+	line(1, "GEDC")
+	line(2, "VERS 5.5.1")
+	line(2, "FORM LINEAGE-LINKED")
+	line(1, "CHAR UTF-8")
+	line(0, "@U1@ SUBM")
+	line(1, "NAME Census transcriber")
+
+	year, _ := strconv.Atoi(c.Year)
+	surname := ""
+	for i, r := range rows {
+		name := strings.TrimSpace(r.Col[schema.Name])
+		if name == "" {
+			continue
+		}
+		line(0, indi(i)+" INDI")
+		var full string
+		full, surname = gedcomName(name, surname)
+		value(1, "NAME", full)
+		sex, age := sexOf(r)
+		line(1, "SEX "+sex)
+
+		a, aged := parser.ParseAge(age)
+		aged = aged && year > 0
+		born := ""
+		if sc.Has(schema.Birthplace) && !sc.Has(schema.BornAbroad) {
+			born = strings.TrimSpace(r.Col[schema.Birthplace])
+		}
+		if aged || born != "" {
+			line(1, "BIRT")
+			if aged {
+				line(2, "DATE ABT "+strconv.Itoa(year-int(a.Years)))
+			}
+			value(2, "PLAC", born)
+		}
+		if sc.Has(schema.Occupation) {
+			value(1, "OCCU", r.Col[schema.Occupation])
+		}
+		line(1, "CENS")
+		value(2, "DATE", c.Year)
+		value(2, "PLAC", strings.Trim(strings.TrimSpace(r.Col[schema.Address])+", "+strings.TrimSpace(c.Header[0]), ", "))
+		if rel := strings.TrimSpace(r.Col[schema.Relation]); rel != "" && sc.Has(schema.Relation) {
+			value(2, "NOTE", "Relation to head: "+rel)
+		}
+		for _, l := range links[i] {
+			line(1, l)
+		}
+	}
+
+	for fi, f := range fams {
+		line(0, fam(fi)+" FAM")
+		husb, wife := f.Head, f.Spouse
+		if sex, _ := sexOf(rows[f.Head]); sex == "F" {
+			husb, wife = wife, husb
+		}
+		if husb >= 0 {
+			line(1, "HUSB "+indi(husb))
+		}
+		if wife >= 0 {
+			line(1, "WIFE "+indi(wife))
+		}
+		for _, ch := range f.Children {
+			line(1, "CHIL "+indi(ch))
+		}
+	}
+	line(0, "TRLR")
+	return os.WriteFile(filename, []byte(b.String()), 0o644)
+}
+
+// sexOf reads a person's sex from the age column filled, M or F, with the
+// age; U and "" when neither is.
+func sexOf(r parser.Row) (sex, age string) {
+	switch {
+	case strings.TrimSpace(r.Col[schema.AgeMale]) != "":
+		return "M", r.Col[schema.AgeMale]
+	case strings.TrimSpace(r.Col[schema.AgeFemale]) != "":
+		return "F", r.Col[schema.AgeFemale]
+	}
+	return "U", ""
+}
+
+// gedcomName writes a name with its last word as the surname, "John
+// /Smith/", and returns the surname. A surname written as ditto ("do") is
+// last, the surname of the person before; a single word is taken as the
+// given name.
+func gedcomName(name, last string) (string, string) {
+	words := strings.Fields(name)
+	if len(words) < 2 {
+		return name, last
+	}
+	surname := words[len(words)-1]
+	if parser.IsDitto(surname) && last != "" {
+		surname = last
+	}
+	return strings.Join(words[:len(words)-1], " ") + " /" + surname + "/", surname
+}
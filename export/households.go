@@ -0,0 +1,119 @@
+package export
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"strings"
+
+	"testme/parser"
+	"testme/schema"
+)
+
+// household is one household block of the JSON export.
+type household struct {
+	Schedule string           `json:"schedule,omitempty"`
+	Address  string           `json:"address,omitempty"`
+	Members  []map[string]any `json:"members"`
+	Family   *family          `json:"family,omitempty"`
+	Warnings []string         `json:"warnings,omitempty"`
+
+	rows []int // members' indexes into the rows written
+}
+
+// family is the inferred relationships of a household, by page row.
+type family struct {
+	Head     int   `json:"head"`
+	Spouse   int   `json:"spouse,omitempty"`
+	Children []int `json:"children,omitempty"`
+}
+
+// WriteHouseholdsJSON writes the body as households, each with its members
+// in page order except that the head comes first. A member is its page row
+// plus every filled field of the year's form, keyed by schema.Keys, and the
+// keys of any uncertain cells under "uncertain". With
+// opts.Relationships each household also links its head's spouse and
+// children by page row, or warns why it could not.
+func WriteHouseholdsJSON(c parser.Census, filename string, opts Options) error {
+	rows, first := c.Rows[:], 0
+	if opts.From > 0 {
+		rows, first = parser.RowRange(rows, opts.From, opts.To), opts.From-1
+	}
+	sc := schema.For(c.Year)
+
+	houses := []household{}
+	for i, start := range parser.HouseholdStarts(rows) {
+		r := rows[i]
+		if r.Col == ([parser.FieldCount]string{}) {
+			continue
+		}
+		if start || len(houses) == 0 {
+			houses = append(houses, household{})
+		}
+		h := &houses[len(houses)-1]
+		if h.Schedule == "" {
+			h.Schedule = r.Col[schema.Sched]
+		}
+		if h.Address == "" {
+			h.Address = r.Col[schema.Address]
+		}
+		member := map[string]any{"row": first + i + 1}
+		var uncertain []string
+		for _, col := range sc.Columns {
+			if v := r.Col[col]; v != "" && col != schema.Sched && col != schema.Address {
+				member[schema.Keys[col]] = v
+			}
+			if r.Uncertain[col] {
+				uncertain = append(uncertain, schema.Keys[col])
+			}
+		}
+		if uncertain != nil {
+			member["uncertain"] = uncertain
+		}
+		h.Members, h.rows = append(h.Members, member), append(h.rows, i)
+	}
+	for i := range houses {
+		if opts.Relationships {
+			houses[i].infer(rows, first)
+		}
+	}
+	for _, h := range houses {
+		slices.SortStableFunc(h.Members, func(a, b map[string]any) int {
+			return headRank(a) - headRank(b)
+		})
+	}
+
+	data, err := json.MarshalIndent(struct {
+		Year       string      `json:"year,omitempty"`
+		Households []household `json:"households"`
+	}{c.Year, houses}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0o644)
+}
+
+// infer fills in the household's family from the Relation column of its
+// rows, the first of which is page row first+1.
+func (h *household) infer(rows []parser.Row, first int) {
+	f := parser.InferFamily(rows, h.rows)
+	h.Warnings = f.Warnings
+	if f.Head < 0 {
+		return
+	}
+	h.Family = &family{Head: first + f.Head + 1}
+	if f.Spouse >= 0 {
+		h.Family.Spouse = first + f.Spouse + 1
+	}
+	for _, c := range f.Children {
+		h.Family.Children = append(h.Family.Children, first+c+1)
+	}
+}
+
+// headRank sorts the head of a household before everyone else.
+func headRank(m map[string]any) int {
+	if rel, _ := m[schema.Keys[schema.Relation]].(string); strings.EqualFold(strings.TrimSpace(rel), "head") {
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,22 @@
+package export
+
+import (
+	"bytes"
+	"os"
+
+	"testme/parser"
+)
+
+// WriteJSON saves the page as JSON in the form parser.EncodeJSON gives it,
+// for a data file that reads back exactly and diffs line by line. Rows
+// outside the range are left out and the rest keep their page row numbers.
+func WriteJSON(c parser.Census, filename string, opts Options) error {
+	if opts.From > 0 {
+		c.Rows = append(make([]parser.Row, opts.From-1), parser.RowRange(c.Rows, opts.From, opts.To)...)
+	}
+	var buf bytes.Buffer
+	if err := parser.EncodeJSON(&buf, c); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, buf.Bytes(), 0o644)
+}
@@ -0,0 +1,25 @@
+package export
+
+import (
+	"encoding/json"
+	"os"
+
+	"testme/parser"
+	tpl "testme/template"
+)
+
+// WriteRefs writes a JSON sidecar mapping the ref id of every non-empty body
+// cell to its row, column and value. The ids are the ones the HTML exporter
+// uses with the same options.
+func WriteRefs(c parser.Census, filename string, opts Options) error {
+	opts.HTML.From, opts.HTML.To = opts.From, opts.To
+	refs := tpl.Refs(c, opts.HTML)
+	if refs == nil {
+		refs = []tpl.CellRef{}
+	}
+	data, err := json.MarshalIndent(refs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0o644)
+}
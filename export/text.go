@@ -0,0 +1,57 @@
+package export
+
+import (
+	"os"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+
+	"testme/parser"
+	"testme/schema"
+)
+
+// Text lays out the non-empty body rows as fixed-width columns, in the form
+// order of the census year, under a line of column labels. Each column is
+// colWidth cells wide; longer values are cut with an ellipsis. Widths are
+// measured in terminal cells, so accented and wide characters line up.
+func Text(c parser.Census, colWidth int) string {
+	colWidth = max(colWidth, 2)
+	sc := schema.For(c.Year)
+	var b strings.Builder
+	line := func(val func(col int) string) {
+		cells := make([]string, len(sc.Columns))
+		for i, col := range sc.Columns {
+			cells[i] = runewidth.FillRight(runewidth.Truncate(val(col), colWidth, "…"), colWidth)
+		}
+		b.WriteString(strings.TrimRight(strings.Join(cells, " "), " ") + "\n")
+	}
+
+	line(func(col int) string { return sc.Labels[col] })
+	line(func(int) string { return strings.Repeat("-", colWidth) })
+	for _, r := range c.Rows {
+		if r.Col != ([parser.FieldCount]string{}) {
+			line(func(col int) string { return r.Col[col] })
+		}
+	}
+	return b.String()
+}
+
+// DefaultTextWidth is the column width of the txt listing unless
+// Options.TextWidth gives another.
+const DefaultTextWidth = 12
+
+// WriteTXT writes the body as a fixed-width listing for printing: a line
+// naming the census year and parish, then the rows as Text lays them out,
+// each column opts.TextWidth cells wide.
+func WriteTXT(c parser.Census, filename string, opts Options) error {
+	width := opts.TextWidth
+	if width <= 0 {
+		width = DefaultTextWidth
+	}
+	c.Rows = parser.RowRange(c.Rows, opts.From, opts.To)
+	title := strings.TrimSpace(c.Year + " Census")
+	if p := strings.TrimSpace(c.Header[0]); p != "" {
+		title += " — " + strings.ReplaceAll(p, "\n", ", ")
+	}
+	return os.WriteFile(filename, []byte(title+"\n\n"+Text(c, width)), 0o644)
+}
@@ -0,0 +1,71 @@
+package export
+
+import (
+	"fmt"
+
+	"testme/parser"
+	"testme/schema"
+)
+
+var (
+	headNames = [parser.HeadCount]string{"Parish", "City", "Ward", "Parl Borough", "Town", "Hamlet", "Ecc District"}
+	footNames = [parser.FootCount]string{"Houses Inhab", "Houses Uninh", "Total Males", "Total Females"}
+)
+
+// Verify reads back the HTML file at path, written from c with opts, and
+// reports the first value that did not survive the trip.
+func Verify(c parser.Census, path string, opts Options) error {
+	got, err := parser.ParseHTML(path)
+	if err != nil {
+		return err
+	}
+	diff := func(what, want, got string) error {
+		return fmt.Errorf("%s: wrote %q, read back %q", what, want, got)
+	}
+	if c.Year != "" && got.Year != c.Year {
+		return diff("year", c.Year, got.Year)
+	}
+	for i := range c.Header {
+		if got.Header[i] != c.Header[i] {
+			return diff(headNames[i], c.Header[i], got.Header[i])
+		}
+	}
+	want, gotMeta := c.Meta.Fields(), got.Meta.Fields()
+	for i := range want {
+		if *gotMeta[i] != *want[i] {
+			return diff(parser.MetaLabels[i], *want[i], *gotMeta[i])
+		}
+	}
+
+	sc := schema.For(c.Year)
+	first := 0
+	rows := c.Rows[:]
+	if opts.From > 0 {
+		first, rows = opts.From-1, parser.RowRange(rows, opts.From, opts.To)
+	}
+	for ri, row := range rows {
+		var back parser.Row
+		if ri < len(got.Rows) {
+			back = got.Rows[ri]
+		}
+		for _, col := range sc.Columns {
+			where := fmt.Sprintf("row %d %s", first+ri+1, sc.Labels[col])
+			if back.Col[col] != row.Col[col] {
+				return diff(where, row.Col[col], back.Col[col])
+			}
+			if k, bk := row.KindOf(sc, col), back.KindOf(sc, col); row.Col[col] != "" && bk != k {
+				return diff(where+" markup", k.String(), bk.String())
+			}
+			if back.Uncertain[col] != row.Uncertain[col] {
+				return diff(where+" uncertain", fmt.Sprint(row.Uncertain[col]), fmt.Sprint(back.Uncertain[col]))
+			}
+		}
+	}
+
+	for i := range c.Footer {
+		if got.Footer[i] != c.Footer[i] {
+			return diff(footNames[i], c.Footer[i], got.Footer[i])
+		}
+	}
+	return nil
+}
@@ -1,15 +1,356 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
+	"testme/config"
+	"testme/export"
+	"testme/parser"
+	"testme/schema"
+	"testme/script"
+	"testme/serve"
+	tpl "testme/template"
 	"testme/ui"
 )
 
 func main() {
-	if err := ui.Start(); err != nil {
+	cellIDs := flag.Bool("ids", false, "add id attributes to the footer totals in HTML output")
+	truncate := flag.Int("truncate", 0, "shorten HTML body cells longer than `n` characters, keeping the full text as a tooltip")
+	separators := flag.Bool("households", false, "rule off households in HTML output")
+	canonical := flag.Bool("canonical", false, "write HTML body cells one per line, for small diffs under version control")
+	theme := flag.String("theme", "plain", "style HTML output with this built-in `theme`: "+strings.Join(tpl.Themes(), ", "))
+	pageTemplate := flag.String("template", "", "write HTML pages with the Go template in this `file` in place of the built-in page")
+	credit := flag.String("credit", "", "print this `line` below the table in HTML output, e.g. a transcriber and copyright")
+	placeLinks := flag.Bool("places", false, "link birthplaces in the HTML written by -export-all to the places index")
+	relationships := flag.Bool("relationships", false, "link each head's spouse and children in the households export, from the Relation column")
+	textWidth := flag.Int("text-width", 0, fmt.Sprintf("make each column of the txt listing `n` cells wide (default %d)", export.DefaultTextWidth))
+	rowCount := flag.Int("row-count", 0, "write the HTML table with exactly `n` body rows, padding with blank rows or dropping trailing blank ones")
+	rows := flag.String("rows", "", "only export body rows in this `range`, e.g. 5-10")
+	exportAll := flag.String("export-all", "", "write the input `file` in every format to this base name and exit")
+	formats := flag.String("formats", "", "comma-separated formats for -export-all (default all: "+strings.Join(export.Formats(), ",")+")")
+	combine := flag.String("combine", "", "write all input files into this one HTML `file`, a page per sheet, and exit")
+	jobs := flag.Int("jobs", runtime.GOMAXPROCS(0), "convert up to `n` files at once when -export-all is given several inputs")
+	force := flag.Bool("force", false, "write an input to -export-all even when its body has errors, such as an age that is not a number")
+	verify := flag.Bool("verify", false, "read the HTML written by -export-all back and report values that differ")
+	normalize := flag.String("normalize", "", "read the census HTML `file`, written by any tool, and save it in this tool's layout to -o, listing what was repaired")
+	outFile := flag.String("o", "", "output `file`: the page the editor saves (default "+ui.DefaultOut+"), or the one -normalize writes")
+	flag.StringVar(outFile, "out", "", "the same as -o")
+	year := flag.String("year", "", "edit a census of this `year`, skipping the year menu")
+	inFile := flag.String("in", "", "open this census `file` in the editor on start")
+	scriptFile := flag.String("script", "", "fill the page from the field = value lines in this `file` before editing or exporting")
+	serveAddr := flag.String("serve", "", "serve the render and parse API on this `address`, e.g. :8080, instead of editing")
+	manifest := flag.String("manifest", "", "after -export-all, write a `file` listing each input's parish, year, rows, outputs and warnings, with totals (CSV for .csv, else JSON)")
+	logFormat := flag.String("log", "", "write a structured record per file to stderr in this `format` (json) for -export-all and -combine")
+	flag.Parse()
+
+	if err := setLogFormat(*logFormat); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(2)
+	}
+
+	if err := tpl.CheckTheme(*theme); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(2)
+	}
+	if err := tpl.CheckTemplate(*pageTemplate); err != nil {
+		fmt.Fprintln(os.Stderr, "Error: -template", err)
+		os.Exit(2)
+	}
+	if _, err := os.Stat(*pageTemplate); *pageTemplate != "" && errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "%s not found; writing the built-in page\n", *pageTemplate)
+	}
+
+	// the HTML options every output path below writes with
+	htmlOpts := tpl.Options{CellIDs: *cellIDs, MaxCellWidth: *truncate, Credit: *credit, Separators: *separators, Canonical: *canonical, RowCount: *rowCount, Theme: *theme, Template: *pageTemplate}
+
+	if *serveAddr != "" {
+		fmt.Println("serving on", *serveAddr)
+		if err := serve.ListenAndServe(*serveAddr, htmlOpts); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+	}
+
+	if *normalize != "" {
+		if *outFile == "" {
+			fmt.Fprintln(os.Stderr, "Error: -normalize needs an output file given with -o")
+			os.Exit(2)
+		}
+		os.Exit(runNormalize(*normalize, *outFile, htmlOpts))
+	}
+
+	if *combine != "" {
+		if flag.NArg() == 0 {
+			fmt.Fprintln(os.Stderr, "Error: -combine needs at least one input file")
+			os.Exit(2)
+		}
+		if *pageTemplate != "" {
+			fmt.Fprintln(os.Stderr, "-template is a single page's layout; -combine writes its sheets in the built-in one")
+		}
+		os.Exit(runCombine(flag.Args(), *combine, htmlOpts))
+	}
+
+	if *exportAll != "" {
+		if flag.NArg() == 0 {
+			fmt.Fprintln(os.Stderr, "Error: -export-all needs at least one input file")
+			os.Exit(2)
+		}
+		opts := export.Options{
+			HTML:          htmlOpts,
+			PlaceLinks:    *placeLinks,
+			Relationships: *relationships,
+			TextWidth:     *textWidth,
+		}
+		if *rows != "" {
+			var err error
+			if opts.From, opts.To, err = export.ParseRange(*rows); err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				os.Exit(2)
+			}
+		}
+		var names []string
+		if *formats != "" {
+			names = strings.Split(*formats, ",")
+		}
+		if flag.NArg() > 1 {
+			os.Exit(runExportBatch(flag.Args(), *exportAll, names, *scriptFile, *manifest, *verify, *force, *jobs, opts))
+		}
+		os.Exit(runExportAll(flag.Arg(0), *exportAll, names, *scriptFile, *manifest, *verify, *force, opts))
+	}
+
+	opts := ui.Options{Out: *outFile, Year: *year, In: *inFile, Template: *pageTemplate}
+	if err := checkEditorOptions(opts); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(2)
+	}
+
+	if *scriptFile != "" {
+		if opts.In == "" {
+			opts.In = flag.Arg(0)
+		}
+		os.Exit(runScript(*scriptFile, opts))
+	}
+
+	if err := ui.Start(opts); err != nil {
 		fmt.Fprintln(os.Stderr, "Error:", err)
 		os.Exit(1)
 	}
 }
+
+// runExportAll converts in to every requested format, writes the manifest
+// if one is named, and returns the exit code.
+func runExportAll(in, base string, names []string, scriptFile, manifest string, verify, force bool, opts export.Options) int {
+	c, results, err := exportFile(in, base, names, scriptFile, verify, force, opts)
+	code := 0
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		code = 1
+	}
+	for _, r := range results {
+		fmt.Println(r)
+		if r.Err != nil {
+			code = 1
+		}
+	}
+	if manifest != "" {
+		if err := writeManifest(manifest, []manifestFile{manifestEntry(in, c, results, err)}); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return 1
+		}
+	}
+	return code
+}
+
+// runExportBatch converts each of ins into the directory dir, named after
+// the input, with up to jobs files in progress at once. Each file's lines are
+// printed together as it finishes, followed by a summary and, if one is
+// named, the manifest of the run; it returns the exit code.
+func runExportBatch(ins []string, dir string, names []string, scriptFile, manifest string, verify, force bool, jobs int, opts export.Options) int {
+	bases := make([]string, len(ins))
+	seen := map[string]string{}
+	for i, in := range ins {
+		bases[i] = filepath.Join(dir, strings.TrimSuffix(filepath.Base(in), filepath.Ext(in)))
+		if prev, ok := seen[bases[i]]; ok {
+			fmt.Fprintf(os.Stderr, "Error: %s and %s would both be written to %s\n", prev, in, bases[i])
+			return 2
+		}
+		seen[bases[i]] = in
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+
+	start := time.Now()
+	var (
+		mu     sync.Mutex // keeps each file's lines together
+		wg     sync.WaitGroup
+		failed int
+		files  = make([]manifestFile, len(ins))
+	)
+	next := make(chan int)
+	for range max(jobs, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				c, results, err := exportFile(ins[i], bases[i], names, scriptFile, verify, force, opts)
+				files[i] = manifestEntry(ins[i], c, results, err)
+				mu.Lock()
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %s: %v\n", ins[i], err)
+				}
+				bad := err != nil
+				for _, r := range results {
+					fmt.Println(r)
+					bad = bad || r.Err != nil
+				}
+				if bad {
+					failed++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range ins {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	fmt.Printf("%d of %d files converted in %s\n", len(ins)-failed, len(ins), time.Since(start).Round(time.Millisecond))
+	if manifest != "" {
+		if err := writeManifest(manifest, files); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return 1
+		}
+		fmt.Println("✓", manifest)
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// exportFile parses in, applies the script if any and writes the requested
+// formats to base, checking the HTML read back when verify is set. It
+// returns the page as parsed. The error is set when the input could not be
+// read, or when its body has errors and force is not set; failed formats
+// carry theirs in the results.
+func exportFile(in, base string, names []string, scriptFile string, verify, force bool, opts export.Options) (parser.Census, []export.Result, error) {
+	start := time.Now()
+	c, err := parser.ParseHTML(in)
+	if err == nil && scriptFile != "" {
+		err = script.ApplyFile(&c, scriptFile)
+	}
+	if err == nil && !force {
+		err = bodyErrors(c)
+	}
+	if err != nil {
+		logFile(in, c, start, err)
+		return c, nil, err
+	}
+	var failed error
+	results := export.WriteAll(c, base, names, opts)
+	for i, r := range results {
+		if r.Err == nil && verify && r.Format == "html" {
+			if err := export.Verify(c, r.Path, opts); err != nil {
+				results[i].Err = fmt.Errorf("does not read back the same: %w", err)
+			}
+		}
+		if results[i].Err != nil {
+			failed = fmt.Errorf("%s: %w", r.Format, results[i].Err)
+		}
+	}
+	logFile(in, c, start, failed)
+	return c, results, nil
+}
+
+// checkEditorOptions reports an -o the editor cannot save to and a -year
+// the year menu does not offer, listing the years it does.
+func checkEditorOptions(opts ui.Options) error {
+	if opts.Out != "" && !strings.EqualFold(filepath.Ext(opts.Out), ".html") {
+		return fmt.Errorf("-o %s: the editor saves an .html file", opts.Out)
+	}
+	if opts.Year == "" {
+		return nil
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	years, _, _ := cfg.YearMenu()
+	if !slices.Contains(years, opts.Year) {
+		return fmt.Errorf("-year %s is not a census year here (have %s)", opts.Year, strings.Join(years, ", "))
+	}
+	return nil
+}
+
+// runScript starts the editor on the page opts.In (or a blank page when it
+// is "") with the script applied, and returns the exit code.
+func runScript(scriptFile string, opts ui.Options) int {
+	var c parser.Census
+	if opts.In != "" {
+		var err error
+		if c, err = parser.ParseHTML(opts.In); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return 1
+		}
+	}
+	if err := script.ApplyFile(&c, scriptFile); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	if err := ui.StartWith(c, opts); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	return 0
+}
+
+// runCombine writes the sheets in ins, in order, to one HTML file and returns
+// the exit code.
+func runCombine(ins []string, out string, opts tpl.Options) int {
+	sheets := make([]parser.Census, 0, len(ins))
+	for _, in := range ins {
+		start := time.Now()
+		c, err := parser.ParseHTML(in)
+		logFile(in, c, start, err)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", in, err)
+			return 1
+		}
+		sheets = append(sheets, c)
+	}
+	if err := tpl.WriteSheets(sheets, out, opts); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	fmt.Printf("✓ %s (%d sheets)\n", out, len(sheets))
+	return 0
+}
+
+// bodyErrors lists the errors in the body of c, or returns nil when there
+// are none.
+func bodyErrors(c parser.Census) error {
+	sc := schema.For(c.Year)
+	errs := parser.Errors(parser.Validate(sc, c.Rows[:]))
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(errs))
+	for i, is := range errs {
+		msgs[i] = is.Describe(sc)
+	}
+	return fmt.Errorf("%d error(s), nothing written: %s", len(errs), strings.Join(msgs, "; "))
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"testme/export"
+	"testme/parser"
+	"testme/schema"
+	tpl "testme/template"
+)
+
+func TestExportFileRefusesErrorsUnlessForced(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "page.html")
+	var row parser.Row
+	row.Col[schema.Sched], row.Col[schema.Name], row.Col[schema.AgeMale] = "1", "John Smith", "forty"
+	f, err := os.Create(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tpl.RenderHTML(f, parser.Census{Year: "1861", Rows: parser.PadRows([]parser.Row{row})}, tpl.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	base := filepath.Join(dir, "out")
+	_, results, err := exportFile(in, base, []string{"html"}, "", false, false, export.Options{})
+	if err == nil || !strings.Contains(err.Error(), "nothing written") {
+		t.Errorf("exporting a page with an unreadable age: error %v, want the errors listed", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("wrote %v for a page with errors", results)
+	}
+	if _, err := os.Stat(base + ".html"); !os.IsNotExist(err) {
+		t.Errorf("%s.html exists after a refused export", base)
+	}
+
+	_, results, err = exportFile(in, base, []string{"html"}, "", false, true, export.Options{})
+	if err != nil || len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("forced export: %v, %v", results, err)
+	}
+	if _, err := os.Stat(base + ".html"); err != nil {
+		t.Errorf("forced export wrote nothing: %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"testme/export"
+	"testme/parser"
+)
+
+// manifestFile is one input's entry in the manifest of a conversion run.
+type manifestFile struct {
+	Input    string   `json:"input"`
+	Parish   string   `json:"parish,omitempty"`
+	Year     string   `json:"year,omitempty"`
+	Rows     int      `json:"rows"`
+	Outputs  []string `json:"outputs"`
+	Error    string   `json:"error,omitempty"`
+	Warnings []string `json:"warnings"`
+}
+
+// manifestTotals sums the entries of a manifest.
+type manifestTotals struct {
+	Files     int `json:"files"`
+	Converted int `json:"converted"`
+	Failed    int `json:"failed"`
+	Rows      int `json:"rows"`
+	Outputs   int `json:"outputs"`
+	Warnings  int `json:"warnings"`
+}
+
+// manifestEntry records the conversion of in, parsed into c, as exportFile
+// reported it.
+func manifestEntry(in string, c parser.Census, results []export.Result, err error) manifestFile {
+	f := manifestFile{Input: in, Parish: c.Header[0], Year: c.Year, Outputs: []string{}, Warnings: []string{}}
+	if err != nil {
+		f.Error = err.Error()
+		return f
+	}
+	f.Rows, f.Warnings = filledRows(c), warnings(c)
+	var failed []string
+	for _, r := range results {
+		if r.Err != nil {
+			failed = append(failed, r.Format+": "+r.Err.Error())
+			continue
+		}
+		f.Outputs = append(f.Outputs, r.Path)
+	}
+	f.Error = strings.Join(failed, "; ")
+	return f
+}
+
+// writeManifest writes files and their totals to path, as CSV when it ends
+// in .csv, with the totals on a last row, and as JSON otherwise.
+func writeManifest(path string, files []manifestFile) error {
+	var t manifestTotals
+	for _, f := range files {
+		t.Files++
+		if f.Error == "" {
+			t.Converted++
+		} else {
+			t.Failed++
+		}
+		t.Rows += f.Rows
+		t.Outputs += len(f.Outputs)
+		t.Warnings += len(f.Warnings)
+	}
+
+	if !strings.EqualFold(filepath.Ext(path), ".csv") {
+		data, err := json.MarshalIndent(struct {
+			Files  []manifestFile `json:"files"`
+			Totals manifestTotals `json:"totals"`
+		}{files, t}, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, append(data, '\n'), 0o644)
+	}
+
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(out)
+	w.Write([]string{"input", "parish", "year", "rows", "outputs", "error", "warnings"})
+	for _, f := range files {
+		w.Write([]string{f.Input, f.Parish, f.Year, strconv.Itoa(f.Rows), strings.Join(f.Outputs, " "), f.Error, strings.Join(f.Warnings, "; ")})
+	}
+	w.Write([]string{
+		"total: " + strconv.Itoa(t.Converted) + " of " + strconv.Itoa(t.Files) + " converted", "", "",
+		strconv.Itoa(t.Rows), strconv.Itoa(t.Outputs), strconv.Itoa(t.Failed) + " failed", strconv.Itoa(t.Warnings),
+	})
+	w.Flush()
+	if err := w.Error(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"testme/export"
+	"testme/parser"
+	tpl "testme/template"
+)
+
+// runNormalize reads the census HTML in, however it was written, and saves it
+// to out in this tool's own layout, listing what was repaired on the way. It
+// returns the exit code.
+func runNormalize(in, out string, opts tpl.Options) int {
+	src, err := os.ReadFile(in)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	c, err := parser.ParseReader(bytes.NewReader(src))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s: %v\n", in, err)
+		return 1
+	}
+	if err := tpl.WriteHTML(c, out, opts); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	if err := export.Verify(c, out, export.Options{HTML: opts}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s does not read back the same: %v\n", out, err)
+		return 1
+	}
+
+	written, err := os.ReadFile(out)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	repairs := repairsOf(c, src)
+	switch {
+	case bytes.Equal(src, written):
+		fmt.Printf("✓ %s (already in canonical form)\n", out)
+	case len(repairs) == 0:
+		fmt.Printf("✓ %s (layout and spacing rewritten)\n", out)
+	default:
+		fmt.Printf("✓ %s\n", out)
+	}
+	for _, r := range repairs {
+		fmt.Println("  repaired:", r)
+	}
+	return 0
+}
+
+// repairsOf lists how the file src, parsed into c, departs from the layout
+// WriteHTML gives it.
+func repairsOf(c parser.Census, src []byte) []string {
+	var r []string
+	if c.Year == "" {
+		r = append(r, "no census year in the file; written as an 1861 page")
+	}
+	for i, l := range c.HeaderLabels {
+		switch {
+		case !c.HeaderMatched[i] && c.Header[i] != "":
+			r = append(r, fmt.Sprintf("header %q found by position, not by its label", tpl.HeaderLabels[i]))
+		case l != "" && !tpl.SameLabel(l, tpl.HeaderLabels[i]):
+			r = append(r, fmt.Sprintf("header label %q written as %q", l, tpl.HeaderLabels[i]))
+		}
+	}
+	for i := 0; i < parser.FootCount; i += 2 {
+		if l := c.FooterLabels[i]; l != "" && !tpl.SameLabel(l, tpl.FooterLabels[i/2]) {
+			r = append(r, fmt.Sprintf("footer label %q written as %q", l, tpl.FooterLabels[i/2]))
+		}
+	}
+	lower := bytes.ToLower(src)
+	if bytes.Contains(lower, []byte("<tr")) && !bytes.Contains(lower, []byte("<tbody")) {
+		r = append(r, "body rows had no <tbody>")
+	}
+	if bytes.Contains(lower, []byte(`class="person"`)) {
+		r = append(r, "definition-list layout written as the table")
+	}
+	return r
+}
@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Age is an age cell read as a number.
+type Age struct {
+	Years  float64 // the age, or the midpoint of a range
+	Approx bool    // the cell was a range or marked uncertain
+}
+
+// approxPrefixes mark an age as uncertain ("abt 40", "c. 40", "~40").
+var approxPrefixes = []string{"about", "abt", "circa", "ca", "c", "~"}
+
+// ParseAge reads an age cell as years. Whole years ("34") and infant ages in
+// months ("7 mo", "3m"), weeks ("2 wks") or days are accepted, as are
+// uncertain ages: with a prefix such as "abt" or "c.", a trailing "?", or a
+// range ("40-45"), which counts as its midpoint. Anything else is reported as
+// not numeric.
+func ParseAge(s string) (Age, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	var a Age
+	if t, ok := strings.CutSuffix(s, "?"); ok {
+		s, a.Approx = strings.TrimSpace(t), true
+	}
+	for _, p := range approxPrefixes {
+		if t, ok := strings.CutPrefix(s, p); ok && (p == "~" || t == "" || !isLetter(t[0])) {
+			s, a.Approx = strings.TrimSpace(strings.TrimPrefix(t, ".")), true
+			break
+		}
+	}
+
+	lo, rest, ok := leadingNumber(s)
+	if !ok {
+		return Age{}, false
+	}
+	hi := lo
+	if t, found := strings.CutPrefix(strings.TrimSpace(rest), "-"); found {
+		if hi, rest, ok = leadingNumber(strings.TrimSpace(t)); !ok || hi < lo {
+			return Age{}, false
+		}
+		a.Approx = true
+	}
+	n := (lo + hi) / 2
+
+	switch unit := strings.TrimSpace(rest); unit {
+	case "":
+		a.Years = n
+	case "m", "mo", "mos", "mth", "mths", "month", "months":
+		a.Years = n / 12
+	case "w", "wk", "wks", "week", "weeks":
+		a.Years = n / 52
+	case "d", "dy", "dys", "day", "days":
+		a.Years = n / 365
+	default:
+		return Age{}, false
+	}
+	return a, true
+}
+
+// leadingNumber splits the number at the start of s from what follows.
+func leadingNumber(s string) (float64, string, bool) {
+	end := 0
+	for end < len(s) && (s[end] >= '0' && s[end] <= '9' || s[end] == '.') {
+		end++
+	}
+	n, err := strconv.ParseFloat(s[:end], 64)
+	if err != nil {
+		return 0, s, false
+	}
+	return n, s[end:], true
+}
@@ -0,0 +1,45 @@
+package parser
+
+import "testing"
+
+func TestParseAge(t *testing.T) {
+	for _, tc := range []struct {
+		in     string
+		years  float64
+		approx bool
+	}{
+		{"34", 34, false},
+		{" 40 ", 40, false},
+		{"abt 40", 40, true},
+		{"about 40", 40, true},
+		{"c. 40", 40, true},
+		{"c40", 40, true},
+		{"circa 40", 40, true},
+		{"~40", 40, true},
+		{"40?", 40, true},
+		{"40-45", 42.5, true},
+		{"40 - 45", 42.5, true},
+		{"7 mo", 7.0 / 12, false},
+		{"3m", 3.0 / 12, false},
+		{"3-6 mo", 4.5 / 12, true},
+		{"2 wks", 2.0 / 52, false},
+		{"10 days", 10.0 / 365, false},
+	} {
+		a, ok := ParseAge(tc.in)
+		if !ok {
+			t.Errorf("ParseAge(%q) rejected it", tc.in)
+			continue
+		}
+		if a.Years != tc.years || a.Approx != tc.approx {
+			t.Errorf("ParseAge(%q) = %v, approx %v; want %v, approx %v", tc.in, a.Years, a.Approx, tc.years, tc.approx)
+		}
+	}
+}
+
+func TestParseAgeRejects(t *testing.T) {
+	for _, in := range []string{"", "abc", "45-40", "forty", "40 yrs old", "abt", "40-", "-40", "c. abc"} {
+		if a, ok := ParseAge(in); ok {
+			t.Errorf("ParseAge(%q) = %v, want it rejected", in, a)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ParseCount reads a whole number such as a footer total, written plainly
+// ("1234") or with its digits grouped in threes by sep ("1,234"). Groups of
+// the wrong length ("12,34") are not a count.
+func ParseCount(s, sep string) (int, bool) {
+	s = strings.TrimSpace(s)
+	if sep != "" && strings.Contains(s, sep) {
+		groups := strings.Split(s, sep)
+		if len(groups[0]) < 1 || len(groups[0]) > 3 {
+			return 0, false
+		}
+		for _, g := range groups[1:] {
+			if len(g) != 3 {
+				return 0, false
+			}
+		}
+		s = strings.Join(groups, "")
+	}
+	if s == "" || strings.Trim(s, "0123456789") != "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s)
+	return n, err == nil
+}
+
+// FormatCount writes n with its digits grouped in threes by sep, or plainly
+// when sep is empty.
+func FormatCount(n int, sep string) string {
+	s := strconv.Itoa(n)
+	if sep == "" || n < 0 {
+		return s
+	}
+	var b strings.Builder
+	for i, d := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
@@ -0,0 +1,52 @@
+package parser
+
+import "testing"
+
+func TestParseCount(t *testing.T) {
+	for _, tc := range []struct {
+		in, sep string
+		n       int
+		ok      bool
+	}{
+		{"1234", "", 1234, true},
+		{"1234", ",", 1234, true},
+		{" 56 ", ",", 56, true},
+		{"1,234", ",", 1234, true},
+		{"1,234,567", ",", 1234567, true},
+		{"1 234", " ", 1234, true},
+		{"1,234", "", 0, false},
+		{"1,234", " ", 0, false},
+		{"12,34", ",", 0, false},
+		{"1234,567", ",", 0, false},
+		{",234", ",", 0, false},
+		{"", ",", 0, false},
+		{"12a", "", 0, false},
+		{"-5", "", 0, false},
+	} {
+		n, ok := ParseCount(tc.in, tc.sep)
+		if n != tc.n || ok != tc.ok {
+			t.Errorf("ParseCount(%q, %q) = %d, %v; want %d, %v", tc.in, tc.sep, n, ok, tc.n, tc.ok)
+		}
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	for _, tc := range []struct {
+		n         int
+		sep, want string
+	}{
+		{0, ",", "0"},
+		{999, ",", "999"},
+		{1234, "", "1234"},
+		{1234, ",", "1,234"},
+		{1234567, ",", "1,234,567"},
+		{123456, " ", "123 456"},
+	} {
+		if got := FormatCount(tc.n, tc.sep); got != tc.want {
+			t.Errorf("FormatCount(%d, %q) = %q, want %q", tc.n, tc.sep, got, tc.want)
+		}
+		if n, ok := ParseCount(FormatCount(tc.n, tc.sep), tc.sep); !ok || n != tc.n {
+			t.Errorf("ParseCount(FormatCount(%d, %q)) = %d, %v", tc.n, tc.sep, n, ok)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"testme/schema"
+)
+
+// csvLeading is the number of label, value lines before the body in the CSV
+// layout: the census year, the header, the page details and the footer.
+const csvLeading = 1 + HeadCount + MetaCount + FootCount
+
+// ParseCSV reads back a page written by the csv export: the label, value
+// lines in their written order, then a line of column labels and one line per
+// body row in the year's column order. The rows are made up with blanks to
+// the end of the last page; more cells on a line than the form has columns
+// is an error wrapping ErrSchemaMismatch.
+func ParseCSV(path string) (Census, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Census{}, fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	if err != nil {
+		return Census{}, err
+	}
+	defer file.Close()
+	return ParseCSVReader(file)
+}
+
+// ParseCSVReader is ParseCSV for CSV read from r.
+func ParseCSVReader(r io.Reader) (Census, error) {
+	var c Census
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	var records [][]string
+	var lines []int // the line each record starts on
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return c, fmt.Errorf("%w: %w", ErrMalformedCSV, err)
+		}
+		line, _ := cr.FieldPos(0)
+		records, lines = append(records, rec), append(lines, line)
+	}
+	if len(records) < csvLeading {
+		return c, fmt.Errorf("%w: %d label lines before the body, want %d", ErrMalformedCSV, len(records), csvLeading)
+	}
+
+	// lines without a value leave the field blank
+	vals := make([]string, csvLeading)
+	for i, rec := range records[:csvLeading] {
+		if len(rec) > 1 {
+			vals[i] = rec[1]
+		}
+	}
+	c.Year = vals[0]
+	copy(c.Header[:], vals[1:])
+	for i, v := range c.Meta.Fields() {
+		*v = vals[1+HeadCount+i]
+	}
+	copy(c.Footer[:], vals[1+HeadCount+MetaCount:])
+
+	// the column labels come next, then the rows
+	first := min(csvLeading+1, len(records))
+	body := records[first:]
+	c.Rows = PadRows(make([]Row, len(body)))
+	sc := schema.For(c.Year)
+	for ri, rec := range body {
+		if len(rec) > len(sc.Columns) {
+			return c, fmt.Errorf("%w: body row %d on line %d has %d cells, more than the %d on the form",
+				ErrSchemaMismatch, ri+1, lines[first+ri], len(rec), len(sc.Columns))
+		}
+		for pos, v := range rec {
+			c.Rows[ri].Col[sc.Columns[pos]] = v
+		}
+	}
+	return c, nil
+}
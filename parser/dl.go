@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"testme/schema"
+)
+
+// dlList reports whether n is a <dl> of the given class, as written by the
+// definition-list layout.
+func dlList(n *html.Node, class string) bool {
+	return n.Type == html.ElementNode && n.Data == "dl" && attr(n, "class") == class
+}
+
+// dlPairs returns the <dt> label and <dd> node of each entry in the list n.
+func dlPairs(n *html.Node, text func(*html.Node) string) (labels []string, dds []*html.Node) {
+	label := ""
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type != html.ElementNode:
+		case c.Data == "dt":
+			label = text(c)
+		case c.Data == "dd":
+			labels, dds = append(labels, label), append(dds, c)
+		}
+	}
+	return labels, dds
+}
+
+// dlRows fills rows from the person lists of a definition-list layout page,
+// placing each value by its <dt> label. A list's data-row gives its row on
+// the page; lists without one follow the row before. A row past MaxRows
+// stops the walk with an error.
+func dlRows(doc *html.Node, sc schema.Schema, rows *[]Row, text func(*html.Node) string) error {
+	fields := map[string]int{}
+	for _, f := range sc.Columns {
+		fields[sc.Labels[f]] = f
+	}
+	ri := -1
+	var err error
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if err != nil {
+			return
+		}
+		if dlList(n, "person") {
+			if r, err := strconv.Atoi(attr(n, "data-row")); err == nil && r >= 1 {
+				ri = r - 1
+			} else {
+				ri++
+			}
+			if err = CheckRow(ri + 1); err != nil {
+				return
+			}
+			for ri >= len(*rows) {
+				*rows = append(*rows, Row{})
+			}
+			r := &(*rows)[ri]
+			labels, dds := dlPairs(n, text)
+			for i, dd := range dds {
+				f, ok := fields[strings.TrimSpace(labels[i])]
+				if !ok {
+					continue
+				}
+				r.Col[f] = text(dd)
+				if full, ok := cellTitle(dd); ok {
+					r.Col[f] = full
+				}
+				if k := cellKind(dd); k != RefDefault && k != DefaultKind(sc, f) {
+					r.Kind[f] = k
+				}
+				r.Uncertain[f] = hasClass(dd, UncertainClass)
+				r.Ref[f] = cellID(dd)
+			}
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return err
+}
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"testme/schema"
+)
+
+func TestErrorKinds(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing.html")
+	badCSV := filepath.Join(dir, "bad.csv")
+	if err := os.WriteFile(badCSV, []byte("just one line\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	badJSON := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(badJSON, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	long := "<tr>" + strings.Repeat("<td>x</td>", len(schema.For("").Columns)+1) + "</tr>"
+
+	for _, tc := range []struct {
+		name string
+		err  func() error
+		want error
+	}{
+		{"missing html", func() error { _, err := ParseHTML(missing); return err }, ErrNotFound},
+		{"missing csv", func() error { _, err := ParseCSV(missing); return err }, ErrNotFound},
+		{"missing json", func() error { _, err := ParseJSON(missing); return err }, ErrNotFound},
+		{"unreadable html", func() error {
+			_, err := ParseReader(iotest.ErrReader(errors.New("disk fault")))
+			return err
+		}, ErrMalformedHTML},
+		{"row too long", func() error {
+			_, err := ParseReader(strings.NewReader("<table><tbody>" + long + "</tbody></table>"))
+			return err
+		}, ErrSchemaMismatch},
+		{"bad csv", func() error { _, err := ParseCSV(badCSV); return err }, ErrMalformedCSV},
+		{"bad json", func() error { _, err := ParseJSON(badJSON); return err }, ErrMalformedJSON},
+	} {
+		err := tc.err()
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.want)
+		}
+		for _, other := range []error{ErrNotFound, ErrMalformedHTML, ErrMalformedCSV, ErrMalformedJSON, ErrSchemaMismatch} {
+			if other != tc.want && errors.Is(err, other) {
+				t.Errorf("%s: %v is also %v", tc.name, err, other)
+			}
+		}
+	}
+}
+
+func TestSchemaMismatchKeepsWhatFitted(t *testing.T) {
+	long := "<tr><td>1</td><td>High Street</td>" + strings.Repeat("<td>x</td>", len(schema.For("").Columns)) + "</tr>"
+	c, err := ParseReader(strings.NewReader("<table><tbody>" + long + "</tbody></table>"))
+	if !errors.Is(err, ErrSchemaMismatch) {
+		t.Fatalf("got %v, want ErrSchemaMismatch", err)
+	}
+	if got := c.Rows[0].Col[schema.Address]; got != "High Street" {
+		t.Errorf("address %q, want the cells that fitted kept", got)
+	}
+}
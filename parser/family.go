@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"testme/schema"
+)
+
+// Family is the relationships inferred within one household from its
+// Relation column. Members are given by their index in the rows passed to
+// InferFamily.
+type Family struct {
+	Head     int // -1 when the household has no single head
+	Spouse   int // -1 when there is no single wife or husband to link
+	Children []int
+
+	// Warnings say what was left unlinked and why.
+	Warnings []string
+}
+
+// spouses and children are the Relation values linked to the head, lower
+// case and without a closing full stop. In-laws, step-children and other
+// kin stay unlinked, since they are not the head's own family.
+var (
+	spouses  = []string{"wife", "husband"}
+	children = []string{"son", "daughter", "dau", "child"}
+)
+
+// InferFamily links the wife or husband and the sons and daughters among
+// members, indexes into rows, to the household's head. Without exactly one
+// head nothing is linked; with more than one spouse the spouse is left
+// unlinked but the children are not.
+func InferFamily(rows []Row, members []int) Family {
+	f := Family{Head: -1, Spouse: -1}
+	var heads, wives, kids []int
+	for _, i := range members {
+		rel := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(rows[i].Col[schema.Relation]), "."))
+		switch {
+		case rel == "head":
+			heads = append(heads, i)
+		case slices.Contains(spouses, rel):
+			wives = append(wives, i)
+		case slices.Contains(children, rel):
+			kids = append(kids, i)
+		}
+	}
+	switch len(heads) {
+	case 0:
+		if len(wives)+len(kids) > 0 {
+			f.Warnings = append(f.Warnings, "no Head; relationships left unlinked")
+		}
+		return f
+	case 1:
+		f.Head = heads[0]
+	default:
+		f.Warnings = append(f.Warnings, fmt.Sprintf("%d Heads; relationships left unlinked", len(heads)))
+		return f
+	}
+	switch len(wives) {
+	case 0:
+	case 1:
+		f.Spouse = wives[0]
+	default:
+		f.Warnings = append(f.Warnings, fmt.Sprintf("%d spouses of the Head; spouse left unlinked", len(wives)))
+	}
+	f.Children = kids
+	return f
+}
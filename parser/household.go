@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"strings"
+
+	"testme/schema"
+)
+
+// HouseholdStarts marks the rows that begin a household: the first filled
+// row, then each new schedule number or, in rows without one, each change of
+// address. Blank rows never start one.
+func HouseholdStarts(rows []Row) []bool {
+	starts := make([]bool, len(rows))
+	var sched, addr string
+	seen := false
+	for i, r := range rows {
+		if r.Col == ([FieldCount]string{}) {
+			continue
+		}
+		s, a := strings.TrimSpace(r.Col[schema.Sched]), strings.TrimSpace(r.Col[schema.Address])
+		if !seen || (s != "" && s != sched) || (s == "" && a != "" && a != addr) {
+			starts[i] = true
+		}
+		if s != "" {
+			sched = s
+		}
+		if a != "" {
+			addr = a
+		}
+		seen = true
+	}
+	return starts
+}
+
+// IsDitto reports whether v is a ditto mark, standing for the value in the
+// row above: "do", "do.", "ditto" or a double quote.
+func IsDitto(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "do", "do.", "ditto", "ditto.", `"`, "〃":
+		return true
+	}
+	return false
+}
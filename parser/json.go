@@ -0,0 +1,178 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"testme/schema"
+)
+
+// sheetJSON is the JSON form of a census page. Fields are keyed by HeadKeys,
+// MetaKeys, FootKeys and schema.Keys; only filled body rows are listed, each
+// with its 1-based page row, any markup overrides as "markup.<field>", any
+// uncertain cells as "uncertain.<field>": "true" and any ids kept from the
+// file the page was read from as "ref.<field>".
+type sheetJSON struct {
+	Year   string              `json:"year,omitempty"`
+	Header map[string]string   `json:"header"`
+	Meta   map[string]string   `json:"meta,omitempty"`
+	Rows   []map[string]string `json:"rows"`
+	Footer map[string]string   `json:"footer"`
+}
+
+// EncodeJSON writes c to w as indented JSON.
+func EncodeJSON(w io.Writer, c Census) error {
+	s := sheetJSON{Year: c.Year, Header: map[string]string{}, Meta: map[string]string{}, Rows: []map[string]string{}, Footer: map[string]string{}}
+	for i, v := range c.Header {
+		s.Header[HeadKeys[i]] = v
+	}
+	for i, v := range c.Meta.Fields() {
+		if *v != "" {
+			s.Meta[MetaKeys[i]] = *v
+		}
+	}
+	for i, v := range c.Footer {
+		s.Footer[FootKeys[i]] = v
+	}
+	for ri, r := range c.Rows {
+		if r.Col == ([FieldCount]string{}) {
+			continue
+		}
+		row := map[string]string{"row": strconv.Itoa(ri + 1)}
+		for f, v := range r.Col {
+			if v != "" {
+				row[schema.Keys[f]] = v
+			}
+			if k := r.Kind[f]; k != RefDefault {
+				row["markup."+schema.Keys[f]] = k.String()
+			}
+			if r.Uncertain[f] {
+				row["uncertain."+schema.Keys[f]] = "true"
+			}
+			if id := r.Ref[f]; id != "" {
+				row["ref."+schema.Keys[f]] = id
+			}
+		}
+		s.Rows = append(s.Rows, row)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// ParseJSON reads the page saved as JSON at path. A file that is not JSON or
+// does not hold a page wraps ErrMalformedJSON.
+func ParseJSON(path string) (Census, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Census{}, fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	if err != nil {
+		return Census{}, err
+	}
+	defer file.Close()
+	c, err := DecodeJSON(file)
+	if err != nil {
+		return c, fmt.Errorf("%w: %w", ErrMalformedJSON, err)
+	}
+	return c, nil
+}
+
+// DecodeJSON reads a page written by EncodeJSON, with the rows made up to
+// whole pages. Unknown keys and rows numbered below 1 or past MaxRows are
+// errors.
+func DecodeJSON(r io.Reader) (Census, error) {
+	var c Census
+	var s sheetJSON
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return c, err
+	}
+	c.Year = s.Year
+	set := func(where string, keys []string, vals map[string]string, dst func(i int, v string)) error {
+		for k, v := range vals {
+			i := slices.Index(keys, k)
+			if i < 0 {
+				return fmt.Errorf("unknown %s field %q", where, k)
+			}
+			dst(i, v)
+		}
+		return nil
+	}
+	if err := set("header", HeadKeys[:], s.Header, func(i int, v string) { c.Header[i] = v }); err != nil {
+		return c, err
+	}
+	if err := set("meta", MetaKeys[:], s.Meta, func(i int, v string) { *c.Meta.Fields()[i] = v }); err != nil {
+		return c, err
+	}
+	if err := set("footer", FootKeys[:], s.Footer, func(i int, v string) { c.Footer[i] = v }); err != nil {
+		return c, err
+	}
+	for _, row := range s.Rows {
+		n, err := strconv.Atoi(row["row"])
+		if err != nil || n < 1 {
+			return c, fmt.Errorf("row %q is not a row number", row["row"])
+		}
+		if err := CheckRow(n); err != nil {
+			return c, err
+		}
+		if n > len(c.Rows) {
+			c.Rows = PadRows(append(c.Rows, make([]Row, n-len(c.Rows))...))
+		}
+		r := &c.Rows[n-1]
+		for k, v := range row {
+			if k == "row" {
+				continue
+			}
+			if field, ok := strings.CutPrefix(k, "markup."); ok {
+				f, ok := schema.Field(field)
+				kind, known := kindNamed(v)
+				if !ok || !known {
+					return c, fmt.Errorf("row %d: bad markup %s = %q", n, field, v)
+				}
+				r.Kind[f] = kind
+				continue
+			}
+			if field, ok := strings.CutPrefix(k, "uncertain."); ok {
+				f, ok := schema.Field(field)
+				if !ok || v != "true" {
+					return c, fmt.Errorf("row %d: bad uncertain %s = %q", n, field, v)
+				}
+				r.Uncertain[f] = true
+				continue
+			}
+			if field, ok := strings.CutPrefix(k, "ref."); ok {
+				f, ok := schema.Field(field)
+				if !ok {
+					return c, fmt.Errorf("row %d: bad ref %s = %q", n, field, v)
+				}
+				r.Ref[f] = v
+				continue
+			}
+			f, ok := schema.Field(k)
+			if !ok {
+				return c, fmt.Errorf("row %d: unknown body field %q", n, k)
+			}
+			r.Col[f] = v
+		}
+	}
+	c.Rows = PadRows(c.Rows)
+	return c, nil
+}
+
+// kindNamed returns the markup kind with element name s.
+func kindNamed(s string) (RefKind, bool) {
+	for k, tag := range refTags {
+		if tag == s {
+			return k, true
+		}
+	}
+	return RefDefault, false
+}
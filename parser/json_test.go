@@ -0,0 +1,42 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"testme/schema"
+)
+
+func TestRowsPastMaxRows(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		read func() error
+	}{
+		{"json", func() error {
+			_, err := DecodeJSON(strings.NewReader(`{"rows":[{"row":"2000000000","name":"x"}]}`))
+			return err
+		}},
+		{"dl", func() error {
+			_, err := ParseReader(strings.NewReader(`<dl class="person" data-row="2000000000"><dt>Name &amp; Surname</dt><dd>x</dd></dl>`))
+			return err
+		}},
+	} {
+		err := tc.read()
+		if !errors.Is(err, ErrTooManyRows) {
+			t.Errorf("%s: got %v, want ErrTooManyRows", tc.name, err)
+		} else if !strings.Contains(err.Error(), "2000000000") {
+			t.Errorf("%s: %q does not name the row", tc.name, err)
+		}
+	}
+}
+
+func TestRowAtMaxRows(t *testing.T) {
+	c, err := DecodeJSON(strings.NewReader(`{"rows":[{"row":"2500","name":"x"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Rows) != MaxRows || c.Rows[MaxRows-1].Col[schema.Name] != "x" {
+		t.Errorf("got %d rows, last name %q", len(c.Rows), c.Rows[len(c.Rows)-1].Col[schema.Name])
+	}
+}
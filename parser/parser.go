@@ -1,37 +1,238 @@
 package parser
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"golang.org/x/net/html"
+
+	"testme/schema"
 )
 
 const (
-	RowCount   = 25
-	FieldCount = 12
+	RowCount   = 25               // body rows on a page of the form
+	MaxRows    = 100 * RowCount   // most body rows a sheet may hold
+	FieldCount = schema.MaxFields // logical body fields; a year's form uses a subset
 	HeadCount  = 7
 	FootCount  = 4
 )
 
-type Row struct{ Col [FieldCount]string }
+// RefKind selects the markup element a body cell is wrapped in when saved.
+type RefKind int
 
-// ParseHTML reads the census HTML at path and returns header, body rows and footer values.
-func ParseHTML(path string) ([HeadCount]string, [RowCount]Row, [FootCount]string, error) {
-	var head [HeadCount]string
-	var rows [RowCount]Row
-	var foot [FootCount]string
+const (
+	RefDefault RefKind = iota // whatever DefaultKind gives for the column
+	RefMark
+	RefPerson
+	RefPlace
+)
+
+var refTags = map[RefKind]string{RefMark: "Mark", RefPerson: "PersonRef", RefPlace: "PlaceRef"}
+
+// String returns the element name written for k.
+func (k RefKind) String() string { return refTags[k] }
+
+// ID returns the id a cell with markup k at row r, column c (both 1-based)
+// is written with when it was read with none: dpRrCc for people, dwRrCc for
+// places and RrCc for other marks.
+func (k RefKind) ID(r, c int) string {
+	switch k {
+	case RefPerson:
+		return fmt.Sprintf("dpR%dC%d", r, c)
+	case RefPlace:
+		return fmt.Sprintf("dwR%dC%d", r, c)
+	default:
+		return fmt.Sprintf("R%dC%d", r, c)
+	}
+}
+
+// DefaultKind returns the markup used for logical column col of the form sc
+// when a cell has no override.
+func DefaultKind(sc schema.Schema, col int) RefKind {
+	switch sc.RefOf(col) {
+	case schema.Person:
+		return RefPerson
+	case schema.Place:
+		return RefPlace
+	default:
+		return RefMark
+	}
+}
+
+type Row struct {
+	Col  [FieldCount]string
+	Kind [FieldCount]RefKind // per-cell override of DefaultKind
+
+	// Uncertain marks cells whose reading of the source is in doubt, such
+	// as an illegible name; they are written with class="uncertain".
+	Uncertain [FieldCount]bool
+
+	// Ref is the id each cell was read with, from the detlnk or ref
+	// attribute of its markup, and is written again unchanged so ids stay
+	// put when rows move; a cell without one is given its position's.
+	Ref [FieldCount]string
+}
+
+// PadRows returns rows made up with blank rows to whole pages of RowCount,
+// one page at least.
+func PadRows(rows []Row) []Row {
+	n := max((len(rows)+RowCount-1)/RowCount, 1) * RowCount
+	return append(rows[:len(rows):len(rows)], make([]Row, n-len(rows))...)
+}
+
+// TrimPages returns rows without the blank pages after the last page holding
+// a filled row, keeping one page at least.
+func TrimPages(rows []Row) []Row {
+	n := len(rows)
+	for n > RowCount && BlankRows(rows[n-RowCount:n]) {
+		n -= RowCount
+	}
+	return rows[:n]
+}
+
+// RowRange returns rows from..to, counted from 1 and inclusive, cut to the
+// rows there are. A from of 0 means every row.
+func RowRange(rows []Row, from, to int) []Row {
+	if from <= 0 {
+		return rows
+	}
+	from, to = min(from, len(rows)+1), min(to, len(rows))
+	return rows[from-1 : max(to, from-1)]
+}
+
+// BlankRows reports whether every row of rows is blank.
+func BlankRows(rows []Row) bool {
+	for _, r := range rows {
+		if r != (Row{}) {
+			return false
+		}
+	}
+	return true
+}
+
+// KindOf returns the markup that will be used for column col of r on the
+// form sc.
+func (r Row) KindOf(sc schema.Schema, col int) RefKind {
+	if r.Kind[col] != RefDefault {
+		return r.Kind[col]
+	}
+	return DefaultKind(sc, col)
+}
+
+// MetaCount is the number of page metadata fields edited beside the header.
+const MetaCount = 2
+
+// MetaLabels names the metadata fields in the order of Meta.Fields.
+var MetaLabels = [MetaCount]string{"Enumerator", "Page"}
+
+// HeadKeys, MetaKeys and FootKeys are stable machine names for the header,
+// metadata and footer fields in array order, for scripts and data formats.
+var (
+	HeadKeys = [HeadCount]string{"parish", "city", "ward", "parl_borough", "town", "hamlet", "ecc_district"}
+	MetaKeys = [MetaCount]string{"enumerator", "page"}
+	FootKeys = [FootCount]string{"houses_inhabited", "houses_uninhabited", "males", "females"}
+)
+
+// Meta is provenance information about a page, kept apart from the seven
+// boundary fields.
+type Meta struct {
+	Enumerator string // who compiled the schedule
+	Page       string // page number within the enumeration book
+}
+
+// Fields returns pointers to the metadata values in MetaLabels order.
+func (m *Meta) Fields() [MetaCount]*string {
+	return [MetaCount]*string{&m.Enumerator, &m.Page}
+}
+
+// Census is the content of one census page as read from disk.
+type Census struct {
+	Year   string // census year named by the file, "" when it names none
+	Header [HeadCount]string
+	Meta   Meta
+	Rows   []Row // the body, in whole pages of RowCount rows
+	Footer [FootCount]string
+
+	// HeaderMatched reports which header fields were located by their label.
+	HeaderMatched [HeadCount]bool
+
+	// HeaderLabels and FooterLabels hold the wording the file uses to
+	// introduce each value, without a trailing "of"; "" where it has none.
+	HeaderLabels [HeadCount]string
+	FooterLabels [FootCount]string
+
+	// Notes say where the page departs from the expected layout without
+	// stopping the read, such as a header of eight fields.
+	Notes []string
+}
+
+// headerLabels lists, per header field, the label words that introduce it.
+// Longer phrases come first so "Parliamentary Borough" is not taken for a city.
+var headerLabels = [HeadCount][]string{
+	{"parish", "township"},
+	{"city or municipal borough", "municipal borough", "city", "borough"},
+	{"municipal ward", "ward"},
+	{"parliamentary borough", "parl borough"},
+	{"town"},
+	{"village or hamlet", "hamlet", "village", "tything"},
+	{"ecclesiastical district", "ecc district", "ecclesiastical"},
+}
 
+// Errors wrapped by ParseHTML, ParseReader, ParseCSV and ParseJSON, for callers to
+// tell causes apart with errors.Is.
+var (
+	ErrNotFound       = errors.New("file not found")
+	ErrMalformedHTML  = errors.New("unreadable HTML")
+	ErrMalformedCSV   = errors.New("unreadable CSV")
+	ErrMalformedJSON  = errors.New("unreadable JSON")
+	ErrSchemaMismatch = errors.New("body does not fit the year's form")
+	ErrTooManyRows    = errors.New("too many body rows")
+)
+
+// CheckRow returns an error wrapping ErrTooManyRows when body row n (1-based)
+// is past the last of the MaxRows a sheet may hold.
+func CheckRow(n int) error {
+	if n > MaxRows {
+		return fmt.Errorf("%w: row %d is past the %d a sheet may hold", ErrTooManyRows, n, MaxRows)
+	}
+	return nil
+}
+
+// ParseHTML reads the census HTML at path and returns header, body rows and footer values.
+func ParseHTML(path string) (Census, error) {
 	file, err := os.Open(filepath.Clean(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Census{}, fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
 	if err != nil {
-		return head, rows, foot, err
+		return Census{}, err
 	}
 	defer file.Close()
+	return ParseReader(file)
+}
 
-	doc, err := html.Parse(file)
+// ParseReader is ParseHTML for census HTML read from r.
+//
+// Every body row is read, however many pages they fill, and the rows are
+// made up with blanks to the end of the last page. A body row with more
+// cells than the year's form has is reported with ErrSchemaMismatch; the
+// census returned alongside still holds everything that fitted. More rows
+// than MaxRows is an error wrapping ErrTooManyRows.
+func ParseReader(r io.Reader) (Census, error) {
+	var census Census
+	head, rows, foot := &census.Header, &census.Rows, &census.Footer
+
+	doc, err := html.Parse(r)
 	if err != nil {
-		return head, rows, foot, err
+		return census, fmt.Errorf("%w: %w", ErrMalformedHTML, err)
 	}
 
 	text := func(n *html.Node) string {
@@ -53,7 +254,25 @@ func ParseHTML(path string) ([HeadCount]string, [RowCount]Row, [FootCount]string
 	}
 	is := func(n *html.Node, tag string) bool { return n != nil && n.Type == html.ElementNode && n.Data == tag }
 
-	// header
+	// header: look for the boundary labels anywhere outside the body, then
+	// fall back to the positional <br> scan for files with unknown wording.
+	var walkLabels func(*html.Node)
+	walkLabels = func(n *html.Node) {
+		if is(n, "tbody") || is(n, "tfoot") || dlList(n, "person") {
+			return
+		}
+		if n.Type == html.TextNode {
+			if field, label, val, ok := labelValue(n, text); ok && !census.HeaderMatched[field] {
+				head[field], census.HeaderLabels[field] = val, label
+				census.HeaderMatched[field] = true
+			}
+		}
+		for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+			walkLabels(ch)
+		}
+	}
+	walkLabels(doc)
+
 	var ths []*html.Node
 	var collectTh func(*html.Node)
 	collectTh = func(n *html.Node) {
@@ -67,24 +286,58 @@ func ParseHTML(path string) ([HeadCount]string, [RowCount]Row, [FootCount]string
 	collectTh(doc)
 
 	idx := 0
+	if census.HeaderMatched != [HeadCount]bool{} {
+		idx = HeadCount
+	}
 	for _, th := range ths {
 		if idx >= HeadCount {
 			break
 		}
 		for c := th.FirstChild; c != nil; c = c.NextSibling {
 			if is(c, "br") {
-				head[idx] = strings.TrimSpace(text(c.NextSibling))
+				head[idx] = linesAfter(c, text)
+				if c != th.FirstChild && th.FirstChild.Type == html.TextNode {
+					census.HeaderLabels[idx] = trimOf(th.FirstChild.Data)
+				}
 				idx++
 				break
 			}
 		}
 	}
 
-	// body
+	if n, ok := headerCells(doc); ok && n != HeadCount {
+		census.Notes = append(census.Notes, fmt.Sprintf("found %d header fields, expected %d", n, HeadCount))
+	}
+
+	// year and page metadata: an explicit census-year meta tag wins over the
+	// page title
+	var walkMeta func(*html.Node)
+	walkMeta = func(n *html.Node) {
+		switch {
+		case is(n, "meta") && attr(n, "name") == "census-year":
+			census.Year = strings.TrimSpace(attr(n, "content"))
+			return
+		case is(n, "meta") && attr(n, "name") == "census-enumerator":
+			census.Meta.Enumerator = strings.TrimSpace(attr(n, "content"))
+			return
+		case is(n, "meta") && attr(n, "name") == "census-page":
+			census.Meta.Page = strings.TrimSpace(attr(n, "content"))
+			return
+		case is(n, "title") && census.Year == "":
+			census.Year = yearPattern.FindString(text(n))
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walkMeta(c)
+		}
+	}
+	walkMeta(doc)
+
+	// body: cells are placed by the form layout of the file's year
+	sc := schema.For(census.Year)
 	var trs []*html.Node
 	var collectTr func(*html.Node)
 	collectTr = func(n *html.Node) {
-		if is(n, "tr") && ancestorTag(n, "tbody") {
+		if is(n, "tr") && bodyRow(n) {
 			trs = append(trs, n)
 		}
 		for c := n.FirstChild; c != nil; c = c.NextSibling {
@@ -92,41 +345,361 @@ func ParseHTML(path string) ([HeadCount]string, [RowCount]Row, [FootCount]string
 		}
 	}
 	collectTr(doc)
+	if err := CheckRow(len(trs)); err != nil {
+		return census, err
+	}
 
-	for ri := 0; ri < len(trs) && ri < RowCount; ri++ {
+	// a cell spanning columns fills the first and leaves the rest blank, and
+	// the cells a rowspan carries down are left blank on the rows below
+	var mismatch error
+	*rows = make([]Row, len(trs))
+	carried := make([]int, len(sc.Columns)) // rows each column is still taken for
+	for ri := range trs {
+		taken := slices.Clone(carried)
+		for i := range carried {
+			carried[i] = max(carried[i]-1, 0)
+		}
 		td := trs[ri].FirstChild
-		ci := 0
-		for td != nil && ci < FieldCount {
-			if is(td, "td") {
-				rows[ri].Col[ci] = text(td)
-				ci++
+		pos := 0
+		for ; td != nil; td = td.NextSibling {
+			if !is(td, "td") {
+				continue
+			}
+			for pos < len(taken) && taken[pos] > 0 {
+				pos++
+			}
+			cols, down := span(td, "colspan"), span(td, "rowspan")
+			if pos+cols > len(sc.Columns) {
+				if mismatch == nil {
+					mismatch = fmt.Errorf("%w: body row %d has more cells than the %d on the form", ErrSchemaMismatch, ri+1, len(sc.Columns))
+				}
+				break
 			}
-			td = td.NextSibling
+			ci := sc.Columns[pos]
+			(*rows)[ri].Col[ci] = text(td)
+			if full, ok := cellTitle(td); ok {
+				(*rows)[ri].Col[ci] = full
+			}
+			if k := cellKind(td); k != RefDefault && k != DefaultKind(sc, ci) {
+				(*rows)[ri].Kind[ci] = k
+			}
+			(*rows)[ri].Uncertain[ci] = hasClass(td, UncertainClass)
+			(*rows)[ri].Ref[ci] = cellID(td)
+			for i := pos; i < pos+cols; i++ {
+				carried[i] = max(carried[i], down-1)
+			}
+			pos += cols
+		}
+	}
+
+	// pages in the definition-list layout have no body table
+	if len(trs) == 0 {
+		if err := dlRows(doc, sc, rows, text); err != nil {
+			return census, err
 		}
 	}
+	*rows = PadRows(*rows)
 
-	// footer
-	var footvals []string
+	// footer: values are the cells without a colspan, labels the spanning
+	// cells before them; or the entries of the footer definition list
+	var footvals, footlabels []string
+	var footcols []int // column each value sits under, -1 in a list
+	label := ""
 	var walkFooter func(*html.Node)
 	walkFooter = func(n *html.Node) {
+		if dlList(n, "census-footer") {
+			labels, dds := dlPairs(n, text)
+			for i, dd := range dds {
+				footvals, footlabels = append(footvals, text(dd)), append(footlabels, labels[i])
+				footcols = append(footcols, -1)
+			}
+			return
+		}
 		if is(n, "td") && ancestorTag(n, "tfoot") {
-			for _, a := range n.Attr {
-				if a.Key == "colspan" {
-					return
+			if attr(n, "colspan") != "" {
+				if t := text(n); t != "" {
+					label = t
 				}
+				return
 			}
 			footvals = append(footvals, text(n))
+			footlabels = append(footlabels, label)
+			footcols = append(footcols, column(n))
 		}
 		for c := n.FirstChild; c != nil; c = c.NextSibling {
 			walkFooter(c)
 		}
 	}
 	walkFooter(doc)
-	for i := 0; i < len(footvals) && i < FootCount; i++ {
-		foot[i] = footvals[i]
+	order := footOrder(sc, footcols)
+	for i := 0; i < len(order) && i < FootCount; i++ {
+		foot[i], census.FooterLabels[i] = footvals[order[i]], footlabels[order[i]]
 	}
 
-	return head, rows, foot, nil
+	return census, mismatch
+}
+
+// footColumns are the body fields the footer totals sit under, in footer
+// order.
+var footColumns = [FootCount]int{schema.Inhabited, schema.Uninhabited, schema.AgeMale, schema.AgeFemale}
+
+// footOrder returns, for each footer total, the index of the value cell that
+// holds it. When every total's column of sc has a value cell under it, as on
+// a form like 1841's that puts the uninhabited houses first, the cells are
+// matched by column; otherwise they are taken in the order written.
+func footOrder(sc schema.Schema, cols []int) []int {
+	var order []int
+	for _, f := range footColumns {
+		p := sc.Pos(f)
+		i := slices.Index(cols, p)
+		if p < 0 || i < 0 {
+			order = nil
+			break
+		}
+		order = append(order, i)
+	}
+	if order != nil {
+		return order
+	}
+	for i := range cols {
+		order = append(order, i)
+	}
+	return order
+}
+
+// column returns the column cell n starts in, counting the spans of the
+// cells before it in its row.
+func column(n *html.Node) int {
+	col := 0
+	for c := n.PrevSibling; c != nil; c = c.PrevSibling {
+		if c.Type == html.ElementNode && (c.Data == "td" || c.Data == "th") {
+			col += span(c, "colspan")
+		}
+	}
+	return col
+}
+
+// yearPattern finds a census year (1801 to 1991) in free text.
+var yearPattern = regexp.MustCompile(`\b1[89][0-9]1\b`)
+
+// UncertainClass is the class of a cell whose reading is in doubt.
+const UncertainClass = "uncertain"
+
+// span returns the colspan or rowspan of a cell, 1 when it has none or the
+// value is not a positive number.
+func span(td *html.Node, key string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(attr(td, key)))
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
+
+// hasClass reports whether class is among the classes of n.
+func hasClass(n *html.Node, class string) bool {
+	return slices.Contains(strings.Fields(attr(n, "class")), class)
+}
+
+// attr returns the value of n's attribute key, or "".
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// labelValue reports whether the text node t introduces a header field and, if
+// so, which one, the label wording and the value. The value may follow the
+// label inline ("Parish of Upminster"), after a <br>, in the next <td>, or in
+// the <dd> after a <dt>.
+func labelValue(t *html.Node, text func(*html.Node) string) (field int, label, val string, ok bool) {
+	s := strings.ToLower(strings.TrimSpace(t.Data))
+	field = -1
+	rest := ""
+	for i, labels := range headerLabels {
+		for _, l := range labels {
+			if strings.HasPrefix(s, l) && (len(s) == len(l) || !isLetter(s[len(l)])) {
+				field, rest = i, s[len(l):]
+				break
+			}
+		}
+		if field >= 0 {
+			break
+		}
+	}
+	if field < 0 {
+		return 0, "", "", false
+	}
+
+	parent := t.Parent
+	inDt := parent != nil && parent.Type == html.ElementNode && parent.Data == "dt"
+	of := strings.Index(rest+" ", " of ")
+	if of < 0 && !inDt {
+		return 0, "", "", false
+	}
+
+	raw := strings.TrimSpace(t.Data)
+	label = trimOf(raw)
+	if of >= 0 {
+		// rest is a lower-cased suffix of raw, so offsets carry over.
+		at := len(raw) - len(rest) + of
+		label = strings.TrimSpace(raw[:at])
+		if v := strings.TrimSpace(raw[at+len(" of"):]); v != "" {
+			return field, label, v, true
+		}
+	}
+	for sib := t.NextSibling; sib != nil; sib = sib.NextSibling {
+		if sib.Type == html.ElementNode && sib.Data == "br" {
+			return field, label, linesAfter(sib, text), true
+		}
+	}
+	if parent != nil && parent.Type == html.ElementNode {
+		next := parent.NextSibling
+		for next != nil && next.Type != html.ElementNode {
+			next = next.NextSibling
+		}
+		switch {
+		case inDt && next != nil && next.Data == "dd":
+			return field, label, text(next), true
+		case (parent.Data == "th" || parent.Data == "td") && next != nil && next.Data == "td":
+			return field, label, text(next), true
+		}
+	}
+	return field, label, "", true
+}
+
+// linesAfter reads what follows the <br> br up to the end of its element,
+// each further <br> starting a new line, so a header value written over two
+// lines comes back as two.
+func linesAfter(br *html.Node, text func(*html.Node) string) string {
+	var lines []string
+	var line strings.Builder
+	for n := br.NextSibling; n != nil; n = n.NextSibling {
+		switch {
+		case n.Type == html.ElementNode && n.Data == "br":
+			lines = append(lines, strings.TrimSpace(line.String()))
+			line.Reset()
+		case n.Type == html.TextNode:
+			line.WriteString(n.Data)
+		default:
+			line.WriteString(text(n))
+		}
+	}
+	return strings.TrimSpace(strings.Join(append(lines, strings.TrimSpace(line.String())), "\n"))
+}
+
+// trimOf strips a label of surrounding space, a trailing colon and a
+// trailing "of".
+func trimOf(s string) string {
+	s = strings.TrimSuffix(strings.TrimSpace(s), ":")
+	if l := len(s) - len(" of"); l >= 0 && strings.EqualFold(s[l:], " of") {
+		s = s[:l]
+	}
+	return strings.TrimSpace(s)
+}
+
+func isLetter(b byte) bool { return b >= 'a' && b <= 'z' }
+
+// cellKind returns the markup element wrapping the content of td, if any.
+// The HTML parser lower-cases element names, hence the fold.
+func cellKind(td *html.Node) RefKind {
+	for c := td.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		for k, tag := range refTags {
+			if strings.EqualFold(c.Data, tag) {
+				return k
+			}
+		}
+	}
+	return RefDefault
+}
+
+// cellTitle returns the title attribute of the markup element in td. Cells
+// shortened on write keep their full text there.
+func cellTitle(td *html.Node) (string, bool) {
+	for c := td.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		for _, a := range c.Attr {
+			if a.Key == "title" {
+				return a.Val, true
+			}
+		}
+	}
+	return "", false
+}
+
+// cellID returns the id in the detlnk or ref attribute of the markup element
+// in td, or "" when it has none.
+func cellID(td *html.Node) string {
+	for c := td.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		for _, a := range c.Attr {
+			if a.Key == "detlnk" || a.Key == "ref" {
+				return strings.TrimSpace(a.Val)
+			}
+		}
+	}
+	return ""
+}
+
+// bodyRow reports whether the row n holds body data: it sits inside a table
+// but outside thead/tfoot, is not a household separator and has at least one
+// <td>. This accepts rows whether or not the author wrote an explicit <tbody>.
+func bodyRow(n *html.Node) bool {
+	if !ancestorTag(n, "table") || ancestorTag(n, "thead") || ancestorTag(n, "tfoot") || attr(n, "class") == "household-break" {
+		return false
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == "td" {
+			return true
+		}
+	}
+	return false
+}
+
+// headerCells counts the boundary fields of the page: the cells of the
+// first table of headings alone, less the title spanning them, or
+// the entries of the header definition list. ok is false when the page has
+// neither.
+func headerCells(doc *html.Node) (n int, ok bool) {
+	for _, t := range elements(doc, "table") {
+		if len(elements(t, "th")) == 0 || len(elements(t, "td")) > 0 {
+			continue
+		}
+		for _, th := range elements(t, "th") {
+			if attr(th, "colspan") == "" {
+				n++
+			}
+		}
+		return n, true
+	}
+	for _, dl := range elements(doc, "dl") {
+		if dlList(dl, "census-header") {
+			return len(elements(dl, "dd")), true
+		}
+	}
+	return 0, false
+}
+
+// elements returns the descendants of n with the given tag name, in
+// document order.
+func elements(n *html.Node, tag string) []*html.Node {
+	var found []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == tag {
+			found = append(found, c)
+		}
+		found = append(found, elements(c, tag)...)
+	}
+	return found
 }
 
 // ancestorTag reports whether n has an ancestor element with the given tag name.
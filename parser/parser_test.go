@@ -0,0 +1,49 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"testme/schema"
+)
+
+func TestColspanHouses(t *testing.T) {
+	src := `<table><tbody>
+<tr><td>1</td><td>High Street</td><td colspan="2">1</td><td>John Smith</td><td>Head</td></tr>
+</tbody></table>`
+	c, err := ParseReader(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := c.Rows[0].Col
+	want := map[int]string{
+		schema.Sched:       "1",
+		schema.Address:     "High Street",
+		schema.Inhabited:   "1",
+		schema.Uninhabited: "",
+		schema.Name:        "John Smith",
+		schema.Relation:    "Head",
+	}
+	for f, v := range want {
+		if r[f] != v {
+			t.Errorf("%s = %q, want %q", schema.Keys[f], r[f], v)
+		}
+	}
+}
+
+func TestRowspanCarriedDown(t *testing.T) {
+	src := `<table><tbody>
+<tr><td rowspan="2">1</td><td rowspan="2">High Street</td><td>1</td><td></td><td>John Smith</td></tr>
+<tr><td></td><td></td><td>Mary Smith</td></tr>
+</tbody></table>`
+	c, err := ParseReader(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Rows[1].Col[schema.Name]; got != "Mary Smith" {
+		t.Errorf("row 2 name %q, want Mary Smith", got)
+	}
+	if got := c.Rows[1].Col[schema.Address]; got != "" {
+		t.Errorf("row 2 address %q, want it left blank under the rowspan", got)
+	}
+}
@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// peekLimit caps how much of a file Peek reads; a full 25-row page is well
+// under it.
+const peekLimit = 256 << 10
+
+// Summary is a quick look at a census file, enough to tell files apart.
+type Summary struct {
+	Year   string
+	Parish string
+	Rows   int // body rows with any value filled in
+}
+
+// ErrUnrecognized is returned by Peek for files that do not look like a
+// census page.
+var ErrUnrecognized = errors.New("unrecognized format")
+
+// Peek summarises the census HTML at path, reading at most peekLimit bytes.
+func Peek(path string) (Summary, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return Summary{}, err
+	}
+	defer file.Close()
+
+	c, err := ParseReader(io.LimitReader(file, peekLimit))
+	if err != nil {
+		return Summary{}, err
+	}
+	s := Summary{Year: c.Year, Parish: c.Header[0]}
+	for _, r := range c.Rows {
+		if r.Col != ([FieldCount]string{}) {
+			s.Rows++
+		}
+	}
+	if s.Rows == 0 && c.HeaderMatched == [HeadCount]bool{} {
+		return s, ErrUnrecognized
+	}
+	return s, nil
+}
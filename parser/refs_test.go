@@ -0,0 +1,36 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"testme/schema"
+)
+
+func TestIdsKeptOnRead(t *testing.T) {
+	src := `<table><tbody><tr><td></td><td></td><td></td><td></td>` +
+		`<td><PersonRef detlnk="dpR1C5">John Smith</PersonRef></td><td><Mark ref="X7">Head</Mark></td></tr></tbody></table>`
+	c, err := ParseReader(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Rows[0].Ref[schema.Name]; got != "dpR1C5" {
+		t.Errorf("name id %q, want dpR1C5 as read", got)
+	}
+	if got := c.Rows[0].Ref[schema.Relation]; got != "X7" {
+		t.Errorf("relation id %q, want X7", got)
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeJSON(&buf, c); err != nil {
+		t.Fatal(err)
+	}
+	back, err := DecodeJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.Rows[0].Ref != c.Rows[0].Ref {
+		t.Errorf("ids after JSON: %q, want %q", back.Rows[0].Ref, c.Rows[0].Ref)
+	}
+}
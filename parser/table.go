@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"testme/schema"
+)
+
+// lineBreaks turns the tabs and line breaks a quoted field may hold into
+// spaces, as a cell is one line.
+var lineBreaks = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ")
+
+// ReadTable reads delimited text such as a spreadsheet copy into records, one
+// per non-blank line with fields separated by sep and trimmed. A quoted
+// field may hold the separator or a line break, which becomes a space.
+func ReadTable(r io.Reader, sep rune) ([][]string, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = sep
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	var recs [][]string
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return recs, err
+		}
+		if strings.TrimSpace(strings.Join(rec, "")) == "" {
+			continue
+		}
+		for i := range rec {
+			rec[i] = strings.TrimSpace(lineBreaks.Replace(rec[i]))
+		}
+		recs = append(recs, rec)
+	}
+	if len(recs) == 0 {
+		return nil, fmt.Errorf("no rows of data")
+	}
+	return recs, nil
+}
+
+// ParseTable reads body rows from delimited text such as a spreadsheet copy,
+// one row per line with fields separated by sep and laid out as the form of
+// sc. Extra columns are ignored, and the rows are made up with blanks to the
+// end of the last page.
+func ParseTable(r io.Reader, sep rune, sc schema.Schema) (Census, error) {
+	recs, err := ReadTable(r, sep)
+	if err != nil {
+		return Census{}, err
+	}
+	return TableCensus(recs, sc)
+}
+
+// TableCensus lays records read by ReadTable out as body rows of the form sc,
+// as ParseTable does.
+func TableCensus(recs [][]string, sc schema.Schema) (Census, error) {
+	census := Census{Rows: PadRows(make([]Row, len(recs)))}
+	for ri, rec := range recs {
+		for pos := 0; pos < len(rec) && pos < len(sc.Columns); pos++ {
+			census.Rows[ri].Col[sc.Columns[pos]] = rec[pos]
+		}
+	}
+	return census, nil
+}
@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"testme/schema"
+)
+
+// Issue is a body cell worth a second look. A warning is only that; an
+// error is a value that cannot be right, such as an age that is not a
+// number, and can stop the page being published.
+type Issue struct {
+	Row, Col int // 0-based body row and logical field
+	Msg      string
+	Error    bool
+}
+
+// Validate runs every body check over rows laid out as sc, returning the
+// issues in sheet order: down the rows, and along each row in form order.
+func Validate(sc schema.Schema, rows []Row) []Issue {
+	var out []Issue
+	add := func(ri, f int, isErr bool, format string, a ...any) {
+		out = append(out, Issue{ri, f, fmt.Sprintf(format, a...), isErr})
+	}
+	// a schedule number may be repeated down its household's rows, but not
+	// come back after a row without it
+	schedRow := map[string]int{} // first row of each schedule number
+	last := ""
+	for ri, r := range rows {
+		if r.Col == ([FieldCount]string{}) {
+			continue
+		}
+		if strings.TrimSpace(r.Col[schema.Sched]) == "" {
+			last = ""
+		}
+		for _, f := range sc.Columns {
+			v := r.Col[f]
+			switch {
+			case v == "":
+			case f == schema.Sched:
+				s := strings.TrimSpace(v)
+				if first, ok := schedRow[s]; ok && s != last {
+					add(ri, f, true, "schedule %s is also on row %d", s, first+1)
+				} else if !ok {
+					schedRow[s] = ri
+				}
+				last = s
+			case !sc.Usual(f, v):
+				add(ri, f, false, "not a usual value")
+			case f == schema.AgeMale || f == schema.AgeFemale:
+				if _, ok := ParseAge(v); !ok {
+					add(ri, f, true, "not an age, e.g. 34 or 7 mo")
+				} else if f == schema.AgeFemale && r.Col[schema.AgeMale] != "" {
+					add(ri, f, false, "both a male and a female age")
+				}
+			}
+		}
+	}
+	return out
+}
+
+// Errors returns the issues that are errors.
+func Errors(issues []Issue) []Issue {
+	var errs []Issue
+	for _, is := range issues {
+		if is.Error {
+			errs = append(errs, is)
+		}
+	}
+	return errs
+}
+
+// Describe names the cell of the issue by row and field label, then the
+// issue: "R3 Age♂: not an age, e.g. 34 or 7 mo".
+func (is Issue) Describe(sc schema.Schema) string {
+	return fmt.Sprintf("R%d %s: %s", is.Row+1, sc.Labels[is.Col], is.Msg)
+}
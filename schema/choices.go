@@ -0,0 +1,58 @@
+package schema
+
+import (
+	"regexp"
+	"strings"
+)
+
+// choiceSep splits a cell of ticked choices: "Blind, Lunatic", "Blind &
+// Lunatic" and "Blind/Lunatic" all name two.
+var choiceSep = regexp.MustCompile(`\s*[,;&/]\s*`)
+
+// SplitChoices reads v as ticks among the choices of field f: on[i] reports
+// whether choice i is named, matching case, hyphens and a closing full stop
+// loosely, and rest keeps the parts that name none of them, as written.
+func (s Schema) SplitChoices(f int, v string) (on []bool, rest []string) {
+	choices := s.Choices[f]
+	on = make([]bool, len(choices))
+	if strings.TrimSpace(v) == "" {
+		return on, nil
+	}
+	for _, part := range choiceSep.Split(strings.TrimSpace(v), -1) {
+		if part == "" {
+			continue
+		}
+		i := choiceIndex(choices, part)
+		if i < 0 {
+			rest = append(rest, part)
+			continue
+		}
+		on[i] = true
+	}
+	return on, rest
+}
+
+// JoinChoices writes the ticked choices of field f in form order, then rest,
+// so every combination has one spelling.
+func (s Schema) JoinChoices(f int, on []bool, rest []string) string {
+	var parts []string
+	for i, c := range s.Choices[f] {
+		if on[i] {
+			parts = append(parts, c)
+		}
+	}
+	return strings.Join(append(parts, rest...), ", ")
+}
+
+// choiceIndex finds part among choices, or returns -1.
+func choiceIndex(choices []string, part string) int {
+	loose := func(s string) string {
+		return strings.ToLower(strings.Join(strings.Fields(strings.ReplaceAll(strings.TrimSuffix(s, "."), "-", " ")), " "))
+	}
+	for i, c := range choices {
+		if loose(c) == loose(part) {
+			return i
+		}
+	}
+	return -1
+}
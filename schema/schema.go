@@ -0,0 +1,339 @@
+// Package schema describes the body columns of each census year.
+//
+// Every year stores its values in the same logical slots (see the field
+// constants below), so code that needs "the name" or "the birthplace" does
+// not care which year is loaded. A Schema says which of those slots a year's
+// form has, in what order they appear, and how they are labelled.
+package schema
+
+import (
+	"slices"
+	"strings"
+)
+
+// Logical body fields. The first twelve follow the 1861 form; later ones are
+// only used by the years that recorded them. 1841 leaves out Sched, Relation
+// and Condition.
+const (
+	Sched = iota
+	Address
+	Inhabited
+	Uninhabited
+	Name
+	Relation
+	Condition
+	AgeMale
+	AgeFemale
+	Occupation
+	Birthplace
+	Infirmity
+
+	// 1911 particulars as to marriage, filled in for married women.
+	YearsMarried
+	BornAlive
+	StillLiving
+	HaveDied
+
+	// 1911 particulars of occupation.
+	Industry
+	Employment
+	AtHome
+
+	// 1841 splits where born into the same county, Y or N in Birthplace,
+	// and Scotland, Ireland or Foreign Parts, S, I or F here.
+	BornAbroad
+
+	MaxFields // number of logical fields
+)
+
+// RefClass says what the values of a body column refer to, which decides
+// the markup they are saved in.
+type RefClass int
+
+const (
+	Other  RefClass = iota // plain marked text
+	Person                 // a person, linked to a person record
+	Place                  // a place, linked to a place record
+)
+
+// Heading is one cell of the column-heading row in the HTML table.
+type Heading struct {
+	Text  string
+	Class string
+}
+
+// Schema is the body layout of one census year.
+type Schema struct {
+	Columns  []int             // logical fields on the form, in form order
+	Labels   [MaxFields]string // short labels for the on-screen inputs
+	Headings []Heading         // HTML column headings, one per column
+	Refs     map[int]RefClass  // what a field refers to; absent fields are Other
+	Widths   [MaxFields]int    // on-screen input width a field deserves
+	Vocab    map[int][]string  // usual values of a field, whole words first
+	Suggest  map[int][]string  // more values offered as a field is typed, never flagged
+	Choices  map[int][]string  // fields filled in by ticking from a fixed set
+}
+
+// Usual reports whether v is one of the usual values of field f, ignoring
+// case and a closing full stop. Blank values and fields without a
+// vocabulary are always usual.
+func (s Schema) Usual(f int, v string) bool {
+	v = strings.TrimSuffix(strings.TrimSpace(v), ".")
+	words := s.Vocab[f]
+	if v == "" || len(words) == 0 {
+		return true
+	}
+	for _, w := range words {
+		if strings.EqualFold(w, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Words returns the usual values of field f written out in full, leaving
+// out the abbreviations of earlier ones ("Mar" for "Married").
+func (s Schema) Words(f int) []string {
+	var words []string
+	for _, v := range s.Vocab[f] {
+		short := false
+		for _, w := range words {
+			short = short || strings.HasPrefix(strings.ToLower(w), strings.ToLower(v))
+		}
+		if !short {
+			words = append(words, v)
+		}
+	}
+	return words
+}
+
+// RefOf returns what the values of logical field f refer to.
+func (s Schema) RefOf(f int) RefClass {
+	return s.Refs[f]
+}
+
+// Has reports whether the form includes logical field f.
+func (s Schema) Has(f int) bool {
+	return s.Pos(f) >= 0
+}
+
+// Pos returns the form position of logical field f, or -1.
+func (s Schema) Pos(f int) int {
+	for i, c := range s.Columns {
+		if c == f {
+			return i
+		}
+	}
+	return -1
+}
+
+var labels = [MaxFields]string{
+	"Sched#", "Road / House", "Inhab.", "Uninh.",
+	"Name & Surname", "Relation", "Condition",
+	"Age♂", "Age♀", "Occupation", "Where born", "Blind/Deaf",
+	"Yrs married", "Born alive", "Living", "Died",
+	"Industry", "Employer/Worker", "At home",
+	"S/I/F",
+}
+
+// Keys are stable machine names for the logical fields, for scripts and
+// data exports.
+var Keys = [MaxFields]string{
+	"sched", "address", "inhabited", "uninhabited",
+	"name", "relation", "condition",
+	"age_male", "age_female", "occupation", "birthplace", "infirmity",
+	"years_married", "born_alive", "still_living", "have_died",
+	"industry", "employment", "at_home",
+	"born_abroad",
+}
+
+// Field returns the logical field named key in Keys.
+func Field(key string) (int, bool) {
+	for f, k := range Keys {
+		if k == key {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
+// relabel returns the standard labels with some replaced.
+func relabel(with map[int]string) [MaxFields]string {
+	l := labels
+	for f, s := range with {
+		l[f] = s
+	}
+	return l
+}
+
+// resize returns the standard widths with some replaced.
+func resize(with map[int]int) [MaxFields]int {
+	w := widths
+	for f, n := range with {
+		w[f] = n
+	}
+	return w
+}
+
+// widths gives free-text fields room and keeps numbers and codes narrow.
+var widths = [MaxFields]int{
+	8, 40, 3, 3,
+	40, 16, 10,
+	6, 6, 40, 40, 20,
+	4, 4, 4, 4,
+	30, 12, 8,
+	3,
+}
+
+// Limits is the most characters each field's input takes. Occupations and
+// birthplaces run long ("Agricultural Labourer formerly Journeyman
+// Carpenter"), so they and the name and address get more than the rest.
+var Limits = [MaxFields]int{
+	64, 96, 64, 64,
+	96, 64, 64,
+	64, 64, 128, 128, 64,
+	64, 64, 64, 64,
+	128, 64, 64,
+	64,
+}
+
+// stdVocab holds the conditions written on the forms up to 1901, with the
+// abbreviations enumerators used for them.
+var stdVocab = map[int][]string{
+	Condition: {"Married", "Unmarried", "Widow", "Widower", "Mar", "Unm", "Wid", "M", "U", "W"},
+}
+
+// relations are the relationships to the head common to every form that
+// asks for one.
+var relations = []string{
+	"Head", "Wife", "Son", "Daughter", "Servant", "Lodger", "Visitor", "Boarder",
+	"Grandson", "Granddaughter", "Nephew", "Niece", "Brother", "Sister", "Mother", "Father",
+	"Son-in-law", "Daughter-in-law", "Mother-in-law", "Father-in-law", "Brother-in-law", "Sister-in-law",
+	"Cousin", "Aunt", "Uncle",
+}
+
+// stdRelations are the relationships to the head enumerators wrote up to
+// 1901, whole words before the abbreviations they shortened them to.
+var stdRelations = map[int][]string{
+	Relation: slices.Concat(relations, []string{
+		"Step-son", "Step-daughter", "Apprentice", "Nurse Child", "Inmate",
+		"Dau", "Serv", "Gr Son", "Gr Dau", "Dau-in-law", "Lodg", "Vis",
+	}),
+}
+
+// stdChoices are the infirmities the forms up to 1901 asked after.
+var stdChoices = map[int][]string{
+	Infirmity: {"Blind", "Deaf-and-Dumb", "Imbecile", "Idiot", "Lunatic"},
+}
+
+// stdRefs links names to people and addresses and birthplaces to places.
+var stdRefs = map[int]RefClass{Name: Person, Address: Place, Birthplace: Place}
+
+// std is the 1861 layout, used for every year without its own entry.
+var std = Schema{
+	Columns: []int{Sched, Address, Inhabited, Uninhabited, Name, Relation, Condition, AgeMale, AgeFemale, Occupation, Birthplace, Infirmity},
+	Labels:  labels,
+	Refs:    stdRefs,
+	Widths:  widths,
+	Vocab:   stdVocab,
+	Suggest: stdRelations,
+	Choices: stdChoices,
+	Headings: []Heading{
+		{"Sched. No.", "small-header"},
+		{"Road, Street, & No. or Name of House", "small-header"},
+		{"Houses", "small-header"},
+		{"Name & Surname of each Person", "smaller-header"},
+		{"Relation to Head of Family", "smaller-header"},
+		{"Condition", "smaller-header"},
+		{"Age of", "smaller-header"},
+		{"Age of", "small-header"},
+		{"Rank, Profession, or Occupation", "small-header"},
+		{"Where Born", "small-header"},
+		{"Whether Blind or Deaf-and-Dumb", "small-header"},
+		{"", "small-header"},
+	},
+}
+
+var y1911 = Schema{
+	Columns: []int{
+		Sched, Address, Inhabited, Uninhabited, Name, Relation, AgeMale, AgeFemale,
+		Condition, YearsMarried, BornAlive, StillLiving, HaveDied,
+		Occupation, Industry, Employment, AtHome, Birthplace, Infirmity,
+	},
+	Labels: relabel(map[int]string{Condition: "Marriage", Infirmity: "Infirmity"}),
+	Refs:   stdRefs,
+	Widths: widths,
+	Vocab: map[int][]string{
+		Condition:  {"Single", "Married", "Widow", "Widower", "S", "M", "W"},
+		Employment: {"Employer", "Worker", "Own Account"},
+		AtHome:     {"At Home"},
+	},
+	// householders filled the 1911 schedule themselves and wrote the
+	// relationship out
+	Suggest: map[int][]string{
+		Relation: slices.Concat(relations, []string{
+			"Stepson", "Stepdaughter", "Adopted Son", "Adopted Daughter", "Housekeeper",
+		}),
+	},
+	Choices: map[int][]string{
+		Infirmity: {"Totally Deaf", "Deaf and Dumb", "Totally Blind", "Lunatic", "Imbecile", "Feeble-minded"},
+	},
+	Headings: []Heading{
+		{"Schedule No.", "small-header"},
+		{"Road, Street, &c., and No. or Name of House", "small-header"},
+		{"Inhabited", "smaller-header"},
+		{"Uninhabited", "smaller-header"},
+		{"Name and Surname of every Person", "small-header"},
+		{"Relationship to Head of Family", "smaller-header"},
+		{"Age last Birthday: Males", "smaller-header"},
+		{"Age last Birthday: Females", "smaller-header"},
+		{"Particulars as to Marriage: Single, Married, Widower, or Widow", "smaller-header"},
+		{"Completed years the present Marriage has lasted", "smaller-header"},
+		{"Children born alive to present Marriage", "smaller-header"},
+		{"Children still living", "smaller-header"},
+		{"Children who have died", "smaller-header"},
+		{"Personal Occupation", "small-header"},
+		{"Industry or Service with which worker is connected", "small-header"},
+		{"Whether Employer, Worker, or Working on Own Account", "smaller-header"},
+		{"Whether Working at Home", "smaller-header"},
+		{"Where Born", "small-header"},
+		{"Infirmity: Totally Deaf, Deaf and Dumb, Totally Blind, Lunatic, Imbecile, or Feeble-minded", "smaller-header"},
+	},
+}
+
+// y1841 is the first enumeration book: no schedule numbers, relationships
+// or conditions, and birthplaces given only as the county or the country.
+var y1841 = Schema{
+	Columns: []int{Address, Uninhabited, Inhabited, Name, AgeMale, AgeFemale, Occupation, Birthplace, BornAbroad},
+	Labels:  relabel(map[int]string{Address: "Place", Birthplace: "Same county"}),
+	Refs:    map[int]RefClass{Name: Person, Address: Place},
+	Widths:  resize(map[int]int{Birthplace: 3}),
+	Vocab: map[int][]string{
+		Birthplace: {"Y", "N"},
+		BornAbroad: {"S", "I", "F"},
+	},
+	Headings: []Heading{
+		{"Place", "small-header"},
+		{"Houses: Uninhabited or Building", "smaller-header"},
+		{"Houses: Inhabited", "smaller-header"},
+		{"Names of each Person who abode therein the preceding Night", "small-header"},
+		{"Age and Sex: Males", "smaller-header"},
+		{"Age and Sex: Females", "smaller-header"},
+		{"Profession, Trade, Employment, or of Independent Means", "small-header"},
+		{"Whether Born in same County", "smaller-header"},
+		{"Whether Born in Scotland, Ireland, or Foreign Parts", "smaller-header"},
+	},
+}
+
+var byYear = map[string]Schema{
+	"1841": y1841,
+	"1911": y1911,
+}
+
+// For returns the schema of census year y.
+func For(y string) Schema {
+	if s, ok := byYear[y]; ok {
+		return s
+	}
+	return std
+}
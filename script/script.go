@@ -0,0 +1,113 @@
+// Package script fills a census page from a file of assignments, one per
+// line, so a page can be set up without typing it in:
+//
+//	# comments and blank lines are ignored
+//	year = 1861
+//	parish = Great Canfield
+//	enumerator = John Smith
+//	3.name = John Smith
+//	3.age_male = 42
+//	footer.males = 12
+//
+// Body cells are addressed as row.field with the row numbered from 1 and the
+// field one of schema.Keys.
+package script
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"testme/parser"
+	"testme/schema"
+)
+
+// ApplyFile applies the script at path to c.
+func ApplyFile(c *parser.Census, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := Apply(c, f); err != nil {
+		return fmt.Errorf("%s:%w", path, err)
+	}
+	return nil
+}
+
+// Apply reads assignments from r and stores them in c. It stops at the first
+// bad line; the error starts with its line number.
+func Apply(c *parser.Census, r io.Reader) error {
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, val, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("%d: want field = value, got %q", n, line)
+		}
+		if err := assign(c, strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(val)); err != nil {
+			return fmt.Errorf("%d: %w", n, err)
+		}
+	}
+	return sc.Err()
+}
+
+func assign(c *parser.Census, key, val string) error {
+	if key == "year" {
+		c.Year = val
+		return nil
+	}
+	if i := slices.Index(parser.HeadKeys[:], key); i >= 0 {
+		c.Header[i] = val
+		return nil
+	}
+	if i := slices.Index(parser.MetaKeys[:], key); i >= 0 {
+		*c.Meta.Fields()[i] = val
+		return nil
+	}
+	if f, ok := strings.CutPrefix(key, "footer."); ok {
+		if i := slices.Index(parser.FootKeys[:], f); i >= 0 {
+			c.Footer[i] = val
+			return nil
+		}
+		return fmt.Errorf("unknown footer field %q", f)
+	}
+
+	rowStr, field, ok := strings.Cut(key, ".")
+	if !ok {
+		return fmt.Errorf("unknown field %q", key)
+	}
+	row, err := strconv.Atoi(rowStr)
+	if err != nil || row < 1 {
+		return fmt.Errorf("row %q is not a row number", rowStr)
+	}
+	if err := parser.CheckRow(row); err != nil {
+		return err
+	}
+	col, ok := schema.Field(field)
+	if !ok {
+		return fmt.Errorf("unknown body field %q", field)
+	}
+	if !schema.For(c.Year).Has(col) {
+		return fmt.Errorf("field %q is not on the %s form", field, yearName(c.Year))
+	}
+	if row > len(c.Rows) {
+		c.Rows = parser.PadRows(append(c.Rows, make([]parser.Row, row-len(c.Rows))...))
+	}
+	c.Rows[row-1].Col[col] = val
+	return nil
+}
+
+func yearName(y string) string {
+	if y == "" {
+		return "1861"
+	}
+	return y
+}
@@ -0,0 +1,35 @@
+package script
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"testme/parser"
+	"testme/schema"
+)
+
+func TestApply(t *testing.T) {
+	var c parser.Census
+	err := Apply(&c, strings.NewReader("parish = Great Canfield\n30.name = John Smith\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Header[0] != "Great Canfield" {
+		t.Errorf("parish = %q", c.Header[0])
+	}
+	if len(c.Rows) != 2*parser.RowCount || c.Rows[29].Col[schema.Name] != "John Smith" {
+		t.Errorf("got %d rows, row 30 name %q", len(c.Rows), c.Rows[29].Col[schema.Name])
+	}
+}
+
+func TestApplyRowPastMaxRows(t *testing.T) {
+	var c parser.Census
+	err := Apply(&c, strings.NewReader("999999999.name = x\n"))
+	if !errors.Is(err, parser.ErrTooManyRows) || !strings.HasPrefix(err.Error(), "1: ") {
+		t.Errorf("got %v, want ErrTooManyRows on line 1", err)
+	}
+	if len(c.Rows) > parser.MaxRows {
+		t.Errorf("grew to %d rows", len(c.Rows))
+	}
+}
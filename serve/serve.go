@@ -0,0 +1,69 @@
+// Package serve offers rendering and parsing over HTTP, for running the
+// converter as a service:
+//
+//	POST /render  census JSON in, page HTML out
+//	POST /parse   page HTML in, census JSON out
+//
+// The JSON is the form written by parser.EncodeJSON. It lives apart from
+// the editor so only the -serve mode pulls in net/http.
+package serve
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"testme/parser"
+	tpl "testme/template"
+)
+
+// MaxBody is the largest request body accepted, in bytes.
+const MaxBody = 1 << 20
+
+// Handler returns the API, rendering with opts.
+func Handler(opts tpl.Options) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /render", func(w http.ResponseWriter, r *http.Request) {
+		c, err := parser.DecodeJSON(http.MaxBytesReader(w, r.Body, MaxBody))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var buf bytes.Buffer
+		if err := tpl.RenderHTML(&buf, c, opts); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(buf.Bytes())
+	})
+	mux.HandleFunc("POST /parse", func(w http.ResponseWriter, r *http.Request) {
+		c, err := parser.ParseReader(http.MaxBytesReader(w, r.Body, MaxBody))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var buf bytes.Buffer
+		if err := parser.EncodeJSON(&buf, c); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(buf.Bytes())
+	})
+	return mux
+}
+
+// ListenAndServe serves the API on addr, such as ":8080", until it fails.
+// Slow clients are cut off rather than holding connections open.
+func ListenAndServe(addr string, opts tpl.Options) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           Handler(opts),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+	return srv.ListenAndServe()
+}
@@ -0,0 +1,84 @@
+package template
+
+import (
+	"testme/parser"
+)
+
+// dlTmpl is the linear layout of a sheet: the header, each household and the
+// footer as definition lists, for readers that struggle with the table. The
+// <dt> labels are what the parser matches to read it back.
+const dlTmpl = `<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="UTF-8"><title>{{.Year}} Census</title>
+<meta name="census-year" content="{{.Year}}">
+{{- with .Meta.Enumerator}}
+<meta name="census-enumerator" content="{{.}}">{{end}}
+{{- with .Meta.Page}}
+<meta name="census-page" content="{{.}}">{{end}}
+</head>
+<body>
+<h1>{{.Year}} Census</h1>
+{{- with .Meta.Enumerator}}
+<p>Enumerator: {{.}}</p>{{end}}
+<h2>The undermentioned Houses are situate within the Boundaries of the</h2>
+<dl class="census-header">
+{{- range $i, $l := headLabels}}
+  <dt>{{$l}} of</dt><dd>{{index $.Header $i}}</dd>
+{{- end}}
+</dl>
+{{- range $hi, $house := .Households}}
+<section class="household">
+  <h2>Household {{add $hi 1}}</h2>
+  {{- range $house}}
+  <dl class="person" data-row="{{.Row}}">
+    {{- $p := .}}
+    {{- range $ci := $.Schema.Columns}}{{with index $p.Census.Col $ci}}
+    <dt>{{index $.Schema.Labels $ci}}</dt><dd{{cellClass $p.Census $ci}}>{{wrapCell $.Opts $.Schema $p.Census $p.Index $ci}}</dd>
+    {{- end}}{{end}}
+  </dl>
+  {{- end}}
+</section>
+{{- end}}
+<h2>Totals</h2>
+<dl class="census-footer">
+{{- range $i, $l := dlFootLabels}}
+  <dt>{{$l}}</dt><dd>{{index $.Footer $i}}</dd>
+{{- end}}
+</dl>
+{{- with .Opts.Credit}}
+<p class="credit">{{.}}</p>{{end}}
+</body>
+</html>`
+
+// DLFooterLabels names the footer totals in the definition-list layout.
+var DLFooterLabels = [parser.FootCount]string{
+	"Total of Houses inhabited", "Total of Houses uninhabited", "Total of Males", "Total of Females",
+}
+
+// dlPerson is one filled body row: its page row number, its place among the
+// rows written, and the row itself.
+type dlPerson struct {
+	Row, Index int
+	Census     parser.Row
+}
+
+// WriteDL renders the census as definition lists, one per person grouped by
+// household, instead of a table.
+func WriteDL(c parser.Census, filename string, opts Options) error {
+	d := newPageData(c, opts)
+	var houses [][]dlPerson
+	for i, start := range parser.HouseholdStarts(d.Rows) {
+		r := d.Rows[i]
+		if r.Col == ([parser.FieldCount]string{}) {
+			continue
+		}
+		if start || len(houses) == 0 {
+			houses = append(houses, nil)
+		}
+		houses[len(houses)-1] = append(houses[len(houses)-1], dlPerson{max(opts.From, 1) + i, i, r})
+	}
+	return render(dlTmpl, struct {
+		pageData
+		Households [][]dlPerson
+	}{d, houses}, opts.Canonical, filename)
+}
@@ -0,0 +1,100 @@
+package template
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"testme/parser"
+	"testme/schema"
+)
+
+// placesTmpl is the birthplace index: a section per place, named by its
+// anchor, listing who on the page was born there.
+const placesTmpl = `<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="UTF-8"><title>Places — {{.Year}} Census</title></head>
+<body>
+<h1>Birthplaces</h1>
+<p>Everyone on <a href="{{.Page}}">the {{.Year}} census page</a>, by where they were born.</p>
+{{- range .Places}}
+<h2 id="{{.ID}}">{{.Name}}</h2>
+<ul>
+  {{- range .People}}
+  <li>{{with .Name}}{{.}}{{else}}(no name){{end}}, row {{.Row}}</li>
+  {{- end}}
+</ul>
+{{- end}}
+</body>
+</html>`
+
+// place is one distinct birthplace and the people born there, in page order.
+type place struct {
+	ID, Name string
+	People   []struct {
+		Name string
+		Row  int
+	}
+}
+
+// placeID is the anchor of birthplace p in the places index. Places differing
+// only in case, spacing or punctuation share one.
+func placeID(p string) string {
+	var b strings.Builder
+	b.WriteString("place")
+	dash := true
+	for _, r := range strings.ToLower(p) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if dash {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		default:
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// places collects the distinct birthplaces of rows, sorted by anchor, each
+// named as first written.
+func places(rows []parser.Row, first int) []place {
+	byID := map[string]*place{}
+	var out []*place
+	for i, r := range rows {
+		name := strings.TrimSpace(r.Col[schema.Birthplace])
+		if name == "" {
+			continue
+		}
+		id := placeID(name)
+		p, ok := byID[id]
+		if !ok {
+			p = &place{ID: id, Name: name}
+			byID[id] = p
+			out = append(out, p)
+		}
+		p.People = append(p.People, struct {
+			Name string
+			Row  int
+		}{r.Col[schema.Name], first + i})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	list := make([]place, len(out))
+	for i, p := range out {
+		list[i] = *p
+	}
+	return list
+}
+
+// WritePlaces writes the birthplace index of c to filename. page is the file
+// of the census page itself, linked from the index; the page links to the
+// index when written with Options.PlaceIndex.
+func WritePlaces(c parser.Census, filename, page string, opts Options) error {
+	d := newPageData(c, opts)
+	return render(placesTmpl, struct {
+		Year, Page string
+		Places     []place
+	}{d.Year, page, places(d.Rows, max(opts.From, 1))}, opts.Canonical, filename)
+}
@@ -2,119 +2,561 @@ package template
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	htmlstd "html"
 	"html/template"
+	"io"
+	"io/fs"
 	"os"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
 
 	"testme/parser"
+	"testme/schema"
 )
 
-// wrapCell formats a body cell with HTML markup when saving.
-func wrapCell(v string, row, col int) template.HTML {
+// wrapCell formats a body cell with HTML markup when saving. ri is the
+// cell's row within the rows being written.
+func wrapCell(o Options, sc schema.Schema, row parser.Row, ri, col int) template.HTML {
+	v := row.Col[col]
 	if v == "" {
 		return ""
 	}
-	esc := htmlstd.EscapeString(v)
-	r, c := row+1, col+1
-	switch col {
-	case 4:
-		return template.HTML(fmt.Sprintf(`<PersonRef detlnk="dpR%dC%d">%s</PersonRef>`, r, c, esc))
-	case 1, 10:
-		return template.HTML(fmt.Sprintf(`<PlaceRef detlnk="dwR%dC%d">%s</PlaceRef>`, r, c, esc))
-	default:
-		return template.HTML(fmt.Sprintf(`<Mark ref="R%dC%d">%s</Mark>`, r, c, esc))
+	esc, title := htmlstd.EscapeString(v), ""
+	if o.MaxCellWidth > 0 && utf8.RuneCountInString(v) > o.MaxCellWidth {
+		short := []rune(v)[:max(o.MaxCellWidth-1, 0)]
+		esc, title = htmlstd.EscapeString(string(short))+"…", fmt.Sprintf(` title="%s"`, esc)
 	}
+	k := row.KindOf(sc, col)
+	tag, key := k.String(), "ref"
+	if k != parser.RefMark {
+		key = "detlnk"
+	}
+	if o.PlaceIndex != "" && col == schema.Birthplace {
+		esc = fmt.Sprintf(`<a href="%s#%s">%s</a>`, htmlstd.EscapeString(o.PlaceIndex), placeID(v), esc)
+	}
+	return template.HTML(fmt.Sprintf(`<%s %s="%s"%s>%s</%s>`, tag, key, htmlstd.EscapeString(refOf(row, k, max(o.From, 1)+ri, col)), title, esc, tag))
+}
+
+// CellHTML returns the markup WriteHTML writes for field col of row, the
+// ri'th row written (counting from 0), or "" for a blank cell.
+func CellHTML(row parser.Row, sc schema.Schema, ri, col int, opts Options) string {
+	return string(wrapCell(opts, sc, row, ri, col))
+}
+
+// cellClass returns the class attribute of body cell col of row: the
+// uncertain class when its reading is in doubt, or nothing.
+func cellClass(row parser.Row, col int) template.HTMLAttr {
+	if !row.Uncertain[col] {
+		return ""
+	}
+	return template.HTMLAttr(` class="` + parser.UncertainClass + `"`)
 }
 
+// uncertainCSS styles the uncertain cells, for pages that have any.
+func uncertainCSS(rows []parser.Row) template.CSS {
+	for _, r := range rows {
+		if r.Uncertain != ([parser.FieldCount]bool{}) {
+			return "\n  td.uncertain    { background-color: #fff4c2; text-decoration: underline dotted; }"
+		}
+	}
+	return ""
+}
+
+// refOf returns the id of field col of row, written as the r'th row with
+// markup k: the id it was read with, or else its position's.
+func refOf(row parser.Row, k parser.RefKind, r, col int) string {
+	if row.Ref[col] != "" {
+		return row.Ref[col]
+	}
+	return k.ID(r, col+1)
+}
+
+// CellRef is one non-empty body cell with the id it carries in the HTML.
+type CellRef struct {
+	Ref   string `json:"ref"`
+	Row   int    `json:"row"` // 1-based page row
+	Col   int    `json:"col"` // 1-based logical column, as in the ref
+	Value string `json:"value"`
+
+	// Age is the age read from an age cell, a range counting as its
+	// midpoint; Approx flags ranges and uncertain ages such as "abt 40".
+	Age    *float64 `json:"age,omitempty"`
+	Approx bool     `json:"approximate,omitempty"`
+
+	// Uncertain flags a cell whose reading of the source is in doubt.
+	Uncertain bool `json:"uncertain,omitempty"`
+}
+
+// Refs lists the cells WriteHTML would mark up for c with opts, in row and
+// form order.
+func Refs(c parser.Census, opts Options) []CellRef {
+	d := newPageData(c, opts)
+	var refs []CellRef
+	for ri, row := range d.Rows {
+		for _, col := range d.Schema.Columns {
+			if row.Col[col] == "" {
+				continue
+			}
+			r := max(opts.From, 1) + ri
+			ref := CellRef{Ref: refOf(row, row.KindOf(d.Schema, col), r, col), Row: r, Col: col + 1, Value: row.Col[col], Uncertain: row.Uncertain[col]}
+			if col == schema.AgeMale || col == schema.AgeFemale {
+				if a, ok := parser.ParseAge(row.Col[col]); ok {
+					ref.Age, ref.Approx = &a.Years, a.Approx
+				}
+			}
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// headerVal writes a header value on the line below its label. A value of
+// several lines keeps its line breaks.
 func headerVal(v string) template.HTML {
 	if v == "" {
 		return ""
 	}
-	return template.HTML("<br>" + htmlstd.EscapeString(v))
+	return template.HTML("<br>" + strings.ReplaceAll(htmlstd.EscapeString(v), "\n", "<br>"))
+}
+
+// Options tunes the generated HTML.
+type Options struct {
+	// CellIDs adds id attributes to the footer totals so they can be linked
+	// to and styled individually.
+	CellIDs bool
+
+	// From and To limit the body to rows From..To (1-based, inclusive);
+	// zero values mean every row. Refs keep their full-page row numbers.
+	From, To int
+
+	// MaxCellWidth, when positive, shortens body cell text longer than this
+	// many characters with an ellipsis, keeping the full value in a title
+	// attribute shown as a tooltip.
+	MaxCellWidth int
+
+	// Separators draws a thin rule above the first row of each household
+	// after the first.
+	Separators bool
+
+	// Canonical writes each body cell on a line of its own, so that editing
+	// one value changes one line of a diff.
+	Canonical bool
+
+	// PlaceIndex, when set, links each birthplace to its entry in the places
+	// index written by WritePlaces to this file.
+	PlaceIndex string
+
+	// Theme names a built-in stylesheet added to the page's plain rules,
+	// such as "period"; "" and "plain" add nothing. See Themes.
+	Theme string
+
+	// RowCount, when positive, writes the table with exactly this many body
+	// rows, as on a form with a fixed number of lines: blank rows are added
+	// to make up the count, or trailing blank rows dropped to meet it.
+	RowCount int
+
+	// Credit is a line such as "Transcribed by … • © 2024" printed below
+	// the table; empty leaves it out.
+	Credit string
+
+	// Template, when set, is the path of a page template used in place of
+	// the built-in one, for an archive's own styling or layout. It has the
+	// same functions and may call the "sheet" and "style" templates; a path
+	// naming no file leaves the built-in page in use. See CheckTemplate.
+	Template string
+}
+
+// HeaderLabels is the wording written before each header value, as in
+// "Parish [or Township] of".
+var HeaderLabels = [parser.HeadCount]string{
+	"Parish [or Township]", "City or Municipal Borough", "Municipal Ward", "Parliamentary Borough",
+	"Town", "Village or Hamlet", "Ecclesiastical District",
+}
+
+// FooterLabels introduce the house totals and the male and female totals.
+var FooterLabels = [2]string{"Total of Houses...", "Total of Males and Females..."}
+
+// SameLabel compares label wording ignoring case and trailing dots, colons
+// and spaces, so a file's "Total of Houses" is the standard label.
+func SameLabel(a, b string) bool {
+	trim := func(s string) string { return strings.TrimRight(s, " .:") }
+	return strings.EqualFold(trim(a), trim(b))
+}
+
+// footIDs names the footer total cells when Options.CellIDs is set.
+var footIDs = [parser.FootCount]string{"footHousesInhab", "footHousesUninh", "footMales", "footFemales"}
+
+func footID(o Options, i int) template.HTMLAttr {
+	if !o.CellIDs {
+		return ""
+	}
+	return template.HTMLAttr(fmt.Sprintf(` id="%s"`, footIDs[i]))
+}
+
+// footCell is one cell of the footer row: a total, the label spanning the
+// columns before a pair of totals, or the blank cells after the last.
+type footCell struct {
+	Total int // index into the footer, or -1
+	Span  int // columns a label or the blank run covers
+	Label string
+	Blank bool // the blank run, whose last column gets a cell of its own
+}
+
+// footTotals are the columns the footer totals sit under, in footer order.
+var footTotals = [parser.FootCount]int{schema.Inhabited, schema.Uninhabited, schema.AgeMale, schema.AgeFemale}
+
+// newFootLayout lines the footer totals up under the house and age columns
+// of sc, whatever order the form puts them in, so that the cells span
+// exactly the body's columns.
+func newFootLayout(sc schema.Schema) []footCell {
+	type total struct{ pos, i int }
+	var totals []total
+	for i, f := range footTotals {
+		if p := sc.Pos(f); p >= 0 {
+			totals = append(totals, total{p, i})
+		}
+	}
+	slices.SortFunc(totals, func(a, b total) int { return a.pos - b.pos })
+
+	var cells []footCell
+	next, labelled := 0, [len(FooterLabels)]bool{}
+	for _, t := range totals {
+		if t.pos > next {
+			c := footCell{Total: -1, Span: t.pos - next}
+			if g := t.i / 2; !labelled[g] {
+				c.Label, labelled[g] = FooterLabels[g], true
+			}
+			cells = append(cells, c)
+		}
+		cells = append(cells, footCell{Total: t.i})
+		next = t.pos + 1
+	}
+	if rest := len(sc.Columns) - next; rest > 0 {
+		cells = append(cells, footCell{Total: -1, Span: rest - 1, Blank: true})
+	}
+	return cells
 }
 
 type pageData struct {
+	Year   string
 	Header [parser.HeadCount]string
+	Meta   parser.Meta
+	Schema schema.Schema
 	Rows   []parser.Row
+	Breaks []bool // per row: a new household starts here
 	Footer [parser.FootCount]string
+	Foot   []footCell
+	Opts   Options
 }
 
-const pageTmpl = `<!DOCTYPE html>
-<html lang="en">
-<head><meta charset="UTF-8"><title>1861 Census</title>
-<style>
+// sheetTmpl holds the parts shared by single-page and combined output: the
+// stylesheet rules and the table of one sheet.
+const sheetTmpl = `{{define "style"}}
   .smaller-header { font-size: 8px; }
   .small-header   { font-size: 10px; }
   table, th, td   { border: 1px solid black; border-collapse: collapse; }
   th, td          { padding: 4px; font-size: 12px; }
   thead th        { background-color: #f0f0f0; }
-</style>
-</head>
-<body>
-<!-- HEADER -->
+{{- end}}
+{{- define "sheet"}}<!-- HEADER -->
 <table border="1" cellspacing="0" cellpadding="0">
+  {{- with .Meta.Enumerator}}
+  <caption>Enumerator: {{.}}</caption>{{end}}
   <colgroup><col style="width:8.33%" span="7"></colgroup>
   <thead>
     <tr><th colspan="7" align="center">
       The undermentioned Houses are situate within the Boundaries of the
     </th></tr>
     <tr>
-      <th style="line-height:5em; padding-bottom:2em;">Parish [or Township] of{{headerVal (index .Header 0)}}</th>
-      <th style="line-height:5em; padding-bottom:2em;">City or Municipal Borough of{{headerVal (index .Header 1)}}</th>
-      <th style="line-height:5em; padding-bottom:2em;">Municipal Ward of{{headerVal (index .Header 2)}}</th>
-      <th style="line-height:5em; padding-bottom:2em;">Parliamentary Borough of{{headerVal (index .Header 3)}}</th>
-      <th style="line-height:5em; padding-bottom:2em;">Town of{{headerVal (index .Header 4)}}</th>
-      <th style="line-height:5em; padding-bottom:2em;">Village or Hamlet of{{headerVal (index .Header 5)}}</th>
-      <th style="line-height:5em; padding-bottom:2em;">Ecclesiastical District of{{headerVal (index .Header 6)}}</th>
+      {{- range $i, $l := headLabels}}
+      <th style="line-height:5em; padding-bottom:2em;">{{$l}} of{{headerVal (index $.Header $i)}}</th>
+      {{- end}}
     </tr>
     <tr>
-      <th class="small-header">Sched. No.</th>
-      <th class="small-header">Road, Street, &amp; No. or Name of House</th>
-      <th class="small-header">Houses</th>
-      <th class="smaller-header">Name &amp; Surname of each Person</th>
-      <th class="smaller-header">Relation to Head of Family</th>
-      <th class="smaller-header">Condition</th>
-      <th class="smaller-header">Age of</th>
-      <th class="small-header">Age of</th>
-      <th class="small-header">Rank, Profession, or Occupation</th>
-      <th class="small-header">Where Born</th>
-      <th class="small-header">Whether Blind or Deaf-and-Dumb</th>
-      <th class="small-header"></th>
+      {{- range .Schema.Headings}}
+      <th class="{{.Class}}">{{.Text}}</th>
+      {{- end}}
     </tr>
   </thead>
   <tbody>
     {{range $ri, $row := .Rows}}
-    <tr>{{range $ci, $val := $row.Col}}<td>{{wrapCell $val $ri $ci}}</td>{{end}}</tr>
+    {{- if index $.Breaks $ri}}
+    <tr class="household-break"><td colspan="{{len $.Schema.Columns}}" style="padding:0; border-left:none; border-right:none; border-top:2px solid #999;"></td></tr>
+    {{- end}}
+    {{if $.Opts.Canonical}}<tr>
+      {{- range $ci := $.Schema.Columns}}
+      <td{{cellClass $row $ci}}>{{wrapCell $.Opts $.Schema $row $ri $ci}}</td>
+      {{- end}}
+    </tr>{{else}}<tr>{{range $ci := $.Schema.Columns}}<td{{cellClass $row $ci}}>{{wrapCell $.Opts $.Schema $row $ri $ci}}</td>{{end}}</tr>{{end}}
     {{end}}
   </tbody>
   <!-- FOOTER -->
   <tfoot>
     <tr>
-      <td colspan="2" align="right">Total of Houses...</td>
-      <td>{{index .Footer 0}}</td>
-      <td>{{index .Footer 1}}</td>
-      <td colspan="3" align="right">Total of Males and Females...</td>
-      <td>{{index .Footer 2}}</td>
-      <td>{{index .Footer 3}}</td>
-      <td colspan="2"></td><td></td>
+      {{- range .Foot}}
+      {{- if ge .Total 0}}
+      <td{{footID $.Opts .Total}}>{{index $.Footer .Total}}</td>
+      {{- else if .Blank}}
+      {{with .Span}}<td colspan="{{.}}"></td>{{end}}<td></td>
+      {{- else}}
+      <td colspan="{{.Span}}" align="right">{{.Label}}</td>
+      {{- end}}
+      {{- end}}
     </tr>
   </tfoot>
 </table>
+{{- end}}`
+
+const pageTmpl = `<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="UTF-8"><title>{{.Year}} Census</title>
+<meta name="census-year" content="{{.Year}}">
+{{- with .Meta.Enumerator}}
+<meta name="census-enumerator" content="{{.}}">{{end}}
+{{- with .Meta.Page}}
+<meta name="census-page" content="{{.}}">{{end}}
+<style>{{template "style"}}{{themeCSS .Opts.Theme}}{{uncertainCSS .Rows}}
+</style>
+</head>
+<body>
+{{template "sheet" .}}
+{{- with .Opts.Credit}}
+<p class="credit">{{.}}</p>{{end}}
+</body>
+</html>`
+
+// bookTmpl puts several sheets in one document, each printed on its own page.
+const bookTmpl = `<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="UTF-8"><title>{{.Year}} Census</title>
+<style>{{template "style"}}{{themeCSS .Theme}}{{.Uncertain}}
+  .sheet          { page-break-after: always; }
+  .sheet:last-child { page-break-after: auto; }
+</style>
+</head>
+<body>
+{{- range .Sheets}}
+<div class="sheet">
+{{template "sheet" .}}
+</div>
+{{- end}}
+{{- with .Credit}}
+<p class="credit">{{.}}</p>{{end}}
 </body>
 </html>`
 
-// WriteHTML renders the census data to an HTML file.
-func WriteHTML(header [parser.HeadCount]string, rows []parser.Row, footer [parser.FootCount]string, filename string) error {
-	data := pageData{Header: header, Rows: rows, Footer: footer}
+func newPageData(c parser.Census, opts Options) pageData {
+	rows := parser.RowRange(c.Rows, opts.From, opts.To)
+	sc := schema.For(c.Year)
+	year := c.Year
+	if year == "" {
+		year = "1861"
+	}
+	breaks := make([]bool, len(rows))
+	if opts.Separators {
+		breaks = parser.HouseholdStarts(rows)
+		for i := range breaks {
+			if breaks[i] {
+				breaks[i] = false // no rule above the first household
+				break
+			}
+		}
+	}
+	return pageData{
+		Year: year, Header: c.Header, Meta: c.Meta, Schema: sc, Rows: rows, Breaks: breaks, Footer: c.Footer,
+		Foot: newFootLayout(sc), Opts: opts,
+	}
+}
+
+// tablePage is newPageData for the table layout, sized to opts.RowCount,
+// checking that opts.Theme is built in.
+func tablePage(c parser.Census, opts Options) (pageData, error) {
+	if err := CheckTheme(opts.Theme); err != nil {
+		return pageData{}, err
+	}
+	d := newPageData(c, opts)
+	if opts.RowCount <= 0 {
+		return d, nil
+	}
+	rows, err := setRowCount(d.Rows, opts.RowCount)
+	if err != nil {
+		return d, err
+	}
+	breaks := make([]bool, len(rows))
+	copy(breaks, d.Breaks)
+	d.Rows, d.Breaks = rows, breaks
+	return d, nil
+}
+
+// setRowCount returns rows made up to n with blank rows, or cut to n when
+// the rows beyond it are all blank. Cutting a row with a value in it is an
+// error naming the first such row.
+func setRowCount(rows []parser.Row, n int) ([]parser.Row, error) {
+	if n >= len(rows) {
+		return append(slices.Clip(rows), make([]parser.Row, n-len(rows))...), nil
+	}
+	for i, r := range rows[n:] {
+		if r.Col != ([parser.FieldCount]string{}) {
+			return nil, fmt.Errorf("row %d is not blank, so the body does not fit in %d rows", n+i+1, n)
+		}
+	}
+	return rows[:n], nil
+}
+
+// parsed holds each document template once parsed. A parsed template is
+// safe to execute from several goroutines, so batch conversions share it.
+var (
+	parsedMu sync.Mutex
+	parsed   = map[string]*template.Template{}
+)
+
+// parse returns the document template tmpl, parsing it on first use.
+func parse(tmpl string) (*template.Template, error) {
+	parsedMu.Lock()
+	defer parsedMu.Unlock()
+	if t, ok := parsed[tmpl]; ok {
+		return t, nil
+	}
 	t := template.Must(template.New("page").Funcs(template.FuncMap{
-		"wrapCell":  wrapCell,
-		"headerVal": headerVal,
-	}).Parse(pageTmpl))
+		"wrapCell":     wrapCell,
+		"themeCSS":     themeCSS,
+		"cellClass":    cellClass,
+		"uncertainCSS": uncertainCSS,
+		"headerVal":    headerVal,
+		"footID":       footID,
+		"headLabels":   func() [parser.HeadCount]string { return HeaderLabels },
+		"footLabels":   func() [2]string { return FooterLabels },
+		"dlFootLabels": func() [parser.FootCount]string { return DLFooterLabels },
+		"add":          func(a, b int) int { return a + b },
+	}).Parse(sheetTmpl))
+	if _, err := t.Parse(tmpl); err != nil {
+		return nil, err
+	}
+	parsed[tmpl] = t
+	return t, nil
+}
 
+// pageSource returns the document template of a single page: the file at
+// path, or the built-in page when path is empty or names no file.
+func pageSource(path string) (string, error) {
+	if path == "" {
+		return pageTmpl, nil
+	}
+	src, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return pageTmpl, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(src), nil
+}
+
+// CheckTemplate reports an error for a page template at path that cannot
+// be read or does not parse. An empty path, or one naming no file, is the
+// built-in page.
+func CheckTemplate(path string) error {
+	src, err := pageSource(path)
+	if err != nil {
+		return err
+	}
+	if _, err := parse(src); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// render executes the document template tmpl on data and writes the result
+// to filename.
+func render(tmpl string, data any, canonical bool, filename string) error {
+	out, err := execute(tmpl, data, canonical)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, out, 0o644)
+}
+
+// execute runs the document template tmpl on data. Canonical output loses
+// trailing spaces and blank lines, which the templates leave between rows.
+func execute(tmpl string, data any, canonical bool) ([]byte, error) {
+	t, err := parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
 	var buf bytes.Buffer
 	if err := t.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	out := buf.Bytes()
+	if canonical {
+		var tidy bytes.Buffer
+		for _, line := range bytes.Split(out, []byte("\n")) {
+			if line = bytes.TrimRight(line, " \t"); len(line) > 0 {
+				tidy.Write(append(line, '\n'))
+			}
+		}
+		out = tidy.Bytes()
+	}
+	return out, nil
+}
+
+// WriteHTML renders the census data to an HTML file, in opts.Template when
+// one is given.
+func WriteHTML(c parser.Census, filename string, opts Options) error {
+	d, err := tablePage(c, opts)
+	if err != nil {
 		return err
 	}
-	return os.WriteFile(filename, buf.Bytes(), 0o644)
+	tmpl, err := pageSource(opts.Template)
+	if err != nil {
+		return err
+	}
+	return render(tmpl, d, opts.Canonical, filename)
+}
+
+// RenderHTML writes the census page HTML to w, as WriteHTML saves it.
+func RenderHTML(w io.Writer, c parser.Census, opts Options) error {
+	d, err := tablePage(c, opts)
+	if err != nil {
+		return err
+	}
+	tmpl, err := pageSource(opts.Template)
+	if err != nil {
+		return err
+	}
+	out, err := execute(tmpl, d, opts.Canonical)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// WriteSheets renders several census pages into one HTML file, in order. Each
+// sheet keeps its own header and footer and starts a new printed page; the
+// document is titled after the first sheet's year.
+func WriteSheets(cs []parser.Census, filename string, opts Options) error {
+	if len(cs) == 0 {
+		return fmt.Errorf("no sheets to write")
+	}
+	sheets := make([]pageData, len(cs))
+	for i, c := range cs {
+		var err error
+		if sheets[i], err = tablePage(c, opts); err != nil {
+			return fmt.Errorf("sheet %d: %w", i+1, err)
+		}
+	}
+	var rows []parser.Row
+	for _, d := range sheets {
+		rows = append(rows, d.Rows...)
+	}
+	return render(bookTmpl, struct {
+		Year, Credit, Theme string
+		Uncertain           template.CSS
+		Sheets              []pageData
+	}{sheets[0].Year, opts.Credit, opts.Theme, uncertainCSS(rows), sheets}, opts.Canonical, filename)
 }
@@ -0,0 +1,208 @@
+package template
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+
+	"testme/parser"
+	"testme/schema"
+)
+
+func TestRefTagsFollowSchema(t *testing.T) {
+	var row parser.Row
+	row.Col[schema.Address] = "High Street"
+	row.Col[schema.Name] = "John Smith"
+	row.Col[schema.Occupation] = "Labourer"
+	row.Col[schema.Birthplace] = "Y"
+
+	for _, tc := range []struct {
+		year string
+		want map[int]string
+	}{
+		{"1911", map[int]string{
+			schema.Address:    `<PlaceRef detlnk="dwR1C2">High Street</PlaceRef>`,
+			schema.Name:       `<PersonRef detlnk="dpR1C5">John Smith</PersonRef>`,
+			schema.Occupation: `<Mark ref="R1C10">Labourer</Mark>`,
+			schema.Birthplace: `<PlaceRef detlnk="dwR1C11">Y</PlaceRef>`,
+		}},
+		// 1841 gives only Y or N for the birthplace, which is no place
+		{"1841", map[int]string{
+			schema.Address:    `<PlaceRef detlnk="dwR1C2">High Street</PlaceRef>`,
+			schema.Name:       `<PersonRef detlnk="dpR1C5">John Smith</PersonRef>`,
+			schema.Occupation: `<Mark ref="R1C10">Labourer</Mark>`,
+			schema.Birthplace: `<Mark ref="R1C11">Y</Mark>`,
+		}},
+	} {
+		var buf bytes.Buffer
+		c := parser.Census{Year: tc.year, Rows: parser.PadRows([]parser.Row{row})}
+		if err := RenderHTML(&buf, c, Options{}); err != nil {
+			t.Fatal(err)
+		}
+		for f, cell := range tc.want {
+			if !strings.Contains(buf.String(), cell) {
+				t.Errorf("%s %s: page lacks %s", tc.year, schema.Keys[f], cell)
+			}
+		}
+	}
+}
+
+func TestCanonicalEditChangesOneLine(t *testing.T) {
+	page := func(name string) []string {
+		var rows [3]parser.Row
+		for i := range rows {
+			rows[i].Col[schema.Sched] = "1"
+			rows[i].Col[schema.Name] = "Smith"
+		}
+		rows[1].Col[schema.Name] = name
+		c := parser.Census{Year: "1861", Rows: parser.PadRows(rows[:])}
+		c.Header[0] = "Great Canfield"
+		var buf bytes.Buffer
+		if err := RenderHTML(&buf, c, Options{Canonical: true}); err != nil {
+			t.Fatal(err)
+		}
+		return strings.Split(buf.String(), "\n")
+	}
+	before, after := page("John Smith"), page("Jane Smith")
+	if len(before) != len(after) {
+		t.Fatalf("%d lines became %d", len(before), len(after))
+	}
+	var changed []int
+	for i := range before {
+		if before[i] != after[i] {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) != 1 {
+		t.Fatalf("changed lines %v, want one", changed)
+	}
+	want := `<td><PersonRef detlnk="dpR2C5">Jane Smith</PersonRef></td>`
+	if got := strings.TrimSpace(after[changed[0]]); got != want {
+		t.Errorf("changed line %q, want %q", got, want)
+	}
+}
+
+func TestSetRowCount(t *testing.T) {
+	filled := func(n int, at ...int) []parser.Row {
+		rows := make([]parser.Row, n)
+		for _, i := range at {
+			rows[i].Col[schema.Name] = "John Smith"
+		}
+		return rows
+	}
+
+	got, err := setRowCount(filled(3, 0), 5)
+	if err != nil || len(got) != 5 || got[0].Col[schema.Name] != "John Smith" || got[4] != (parser.Row{}) {
+		t.Errorf("padding 3 rows to 5: %d rows, %v", len(got), err)
+	}
+
+	src := filled(6, 0, 1)
+	got, err = setRowCount(src, 2)
+	if err != nil || len(got) != 2 || got[1].Col[schema.Name] != "John Smith" {
+		t.Errorf("cutting trailing blanks to 2: %d rows, %v", len(got), err)
+	}
+
+	if got, err = setRowCount(filled(4), 4); err != nil || len(got) != 4 {
+		t.Errorf("keeping 4 rows at 4: %d rows, %v", len(got), err)
+	}
+
+	// padding must not write into the caller's spare capacity
+	base := filled(2, 1)
+	if _, err := setRowCount(base[:1], 3); err != nil {
+		t.Fatal(err)
+	}
+	if base[1].Col[schema.Name] != "John Smith" {
+		t.Error("padding wrote over the caller's rows")
+	}
+
+	_, err = setRowCount(filled(6, 0, 4), 3)
+	if err == nil || !strings.Contains(err.Error(), "row 5") {
+		t.Errorf("cutting a filled row: got %v, want an error naming row 5", err)
+	}
+}
+
+func TestMaxCellWidthKeepsFullValue(t *testing.T) {
+	var row parser.Row
+	row.Col[schema.Sched] = "1"
+	row.Col[schema.Name] = "Bartholomew Smith & Sons"
+	opts := Options{MaxCellWidth: 10}
+
+	got := CellHTML(row, schema.For("1861"), 0, schema.Name, opts)
+	want := `<PersonRef detlnk="dpR1C5" title="Bartholomew Smith &amp; Sons">Bartholom…</PersonRef>`
+	if got != want {
+		t.Errorf("long cell written as\n%s, want\n%s", got, want)
+	}
+	if got := CellHTML(row, schema.For("1861"), 0, schema.Sched, opts); strings.Contains(got, "title=") {
+		t.Errorf("short cell given a title: %s", got)
+	}
+
+	var buf bytes.Buffer
+	c := parser.Census{Year: "1861", Rows: parser.PadRows([]parser.Row{row})}
+	if err := RenderHTML(&buf, c, opts); err != nil {
+		t.Fatal(err)
+	}
+	back, err := parser.ParseReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := back.Rows[0].Col[schema.Name]; v != row.Col[schema.Name] {
+		t.Errorf("read back the name as %q, want %q", v, row.Col[schema.Name])
+	}
+}
+
+func TestFooterLinesUpWithColumns(t *testing.T) {
+	for _, year := range []string{"1841", "1861", "1911"} {
+		sc := schema.For(year)
+		c := parser.Census{Year: year, Rows: parser.PadRows(nil), Footer: [parser.FootCount]string{"3", "1", "5", "6"}}
+		var buf bytes.Buffer
+		if err := RenderHTML(&buf, c, Options{}); err != nil {
+			t.Fatal(err)
+		}
+		doc, err := html.Parse(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the column each footer value sits under, and the row's width
+		under, width := map[string]int{}, 0
+		var walk func(*html.Node, bool)
+		walk = func(n *html.Node, foot bool) {
+			foot = foot || n.Type == html.ElementNode && n.Data == "tfoot"
+			if foot && n.Type == html.ElementNode && n.Data == "td" {
+				span := 1
+				for _, a := range n.Attr {
+					if a.Key == "colspan" {
+						span, _ = strconv.Atoi(a.Val)
+					}
+				}
+				if span == 1 && n.FirstChild != nil {
+					under[n.FirstChild.Data] = width
+				}
+				width += span
+			}
+			for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+				walk(ch, foot)
+			}
+		}
+		walk(doc, false)
+
+		if width != len(sc.Columns) {
+			t.Errorf("%s: footer spans %d columns over a body of %d", year, width, len(sc.Columns))
+		}
+		for i, f := range []int{schema.Inhabited, schema.Uninhabited, schema.AgeMale, schema.AgeFemale} {
+			if col, ok := under[c.Footer[i]]; !ok || col != sc.Pos(f) {
+				t.Errorf("%s: %s total under column %d, want %d", year, parser.FootKeys[i], col, sc.Pos(f))
+			}
+		}
+		back, err := parser.ParseReader(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if back.Footer != c.Footer {
+			t.Errorf("%s: footer read back as %q, want %q", year, back.Footer, c.Footer)
+		}
+	}
+}
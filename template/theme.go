@@ -0,0 +1,48 @@
+package template
+
+import (
+	"fmt"
+	"html/template"
+	"slices"
+)
+
+// themes are the stylesheets a page can be dressed in, added after the
+// plain rules every page has. "plain" adds nothing.
+var themes = map[string]string{
+	"plain": "",
+	"period": `
+  /* period: the printed form of the enumeration book */
+  body            { background-color: #f3ead3; color: #2e2418; font-family: Baskerville, "Libre Baskerville", Georgia, "Times New Roman", serif; }
+  table           { background-color: #f8f1de; box-shadow: 0 0 12px #c9b68f inset; }
+  table, th, td   { border-color: #6b5537; }
+  thead th        { background-color: #ece0c2; font-weight: normal; font-variant: small-caps; letter-spacing: 0.03em; }
+  tbody td        { border-top: none; border-bottom: 1px solid #b8a27a; height: 1.6em; font-family: "Palatino Linotype", Palatino, Georgia, serif; font-style: italic; }
+  tfoot td        { border-top: 2px double #6b5537; font-variant: small-caps; }
+  caption, .credit { font-variant: small-caps; color: #5a4630; }`,
+}
+
+// Themes returns the names of the built-in themes, "plain" first.
+func Themes() []string {
+	names := []string{"plain"}
+	for name := range themes {
+		if name != "plain" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names[1:])
+	return names
+}
+
+// CheckTheme reports an error for a theme name that is not built in. The
+// empty name is the plain theme.
+func CheckTheme(name string) error {
+	if _, ok := themes[name]; !ok && name != "" {
+		return fmt.Errorf("unknown theme %q (have %v)", name, Themes())
+	}
+	return nil
+}
+
+// themeCSS returns the rules theme name adds to the stylesheet.
+func themeCSS(name string) template.CSS {
+	return template.CSS(themes[name])
+}
@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"testme/parser"
+	tpl "testme/template"
+)
+
+// autosavePath is where the sheet is backed up, beside the page Ctrl-W
+// writes: census.autosave.html for census.html.
+func (m *model) autosavePath() string { return m.base() + ".autosave.html" }
+
+// autosaveMsg is the tick that asks for a backup.
+type autosaveMsg time.Time
+
+// autosaveTick schedules the next backup, or nothing when backups are off.
+func (m *model) autosaveTick() tea.Cmd {
+	every := m.cfg.AutosaveEvery()
+	if every == 0 {
+		return nil
+	}
+	return m.tick(every, func(t time.Time) tea.Msg { return autosaveMsg(t) })
+}
+
+// autosave backs the sheet up, with the input being typed, when it has
+// changes the saved page does not. Nothing is committed, so the undo steps
+// and the saved page are left alone.
+func (m *model) autosave(at time.Time) {
+	s := m.liveSnap()
+	if m.saved.sameData(s) {
+		return
+	}
+	c := m.census()
+	c.Header, c.Meta, c.Rows, c.Footer = s.header, s.meta, parser.TrimPages(s.rows), s.footer
+	if err := tpl.WriteHTML(c, m.autosavePath(), m.exportOptions().HTML); err != nil {
+		m.autosaveErr = err
+		return
+	}
+	m.autosavedAt, m.autosaveErr = at, nil
+}
+
+// autosaveLine reports the last backup under the form.
+func (m *model) autosaveLine() string {
+	switch {
+	case m.autosaveErr != nil:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render("✗ autosave: " + m.autosaveErr.Error())
+	case !m.autosavedAt.IsZero():
+		return lipgloss.NewStyle().Faint(true).Render("autosaved " + m.autosavedAt.Format("15:04:05"))
+	}
+	return ""
+}
+
+// newerBackup reports whether a backup was written after the saved page last
+// was, so there is work to recover.
+func (m *model) newerBackup() bool {
+	backup, err := os.Stat(m.autosavePath())
+	if err != nil {
+		return false
+	}
+	saved, err := os.Stat(m.out)
+	return err != nil || backup.ModTime().After(saved.ModTime())
+}
+
+// recoverBackup opens the backup in place of a blank sheet. Nothing of it
+// is in the saved page yet, so it all counts as unsaved.
+func (m *model) recoverBackup() {
+	m.setYear(m.years[m.yearIdx])
+	if err := m.loadFromHTML(m.autosavePath()); err != nil {
+		m.notice = loadError(m.autosavePath(), err)
+		return
+	}
+	if m.fileYear != "" {
+		m.setYear(m.fileYear)
+		m.fileYear = ""
+	}
+	m.saved = snapshot{rows: parser.PadRows(nil)}
+	m.switchMode(modeHeader)
+	m.notice = lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Render("✓ recovered " + m.autosavePath())
+}
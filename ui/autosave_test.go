@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"testme/parser"
+	"testme/schema"
+)
+
+func TestAutosaveLeavesEditAlone(t *testing.T) {
+	h := bodyRow(t, "1", "High Street")
+	h.Send(autosaveMsg(time.Now()))
+	if len(h.m.undoStack) != 0 {
+		t.Errorf("autosave pushed %d undo step(s)", len(h.m.undoStack))
+	}
+	if v := h.m.rows[0].Col[schema.Address]; v != "" {
+		t.Errorf("autosave committed the row being typed: %q", v)
+	}
+	c, err := parser.ParseHTML("census.autosave.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := c.Rows[0].Col[schema.Address]; v != "High Street" {
+		t.Errorf("backup has address %q, want what is being typed", v)
+	}
+	if len(h.timers) != 1 {
+		t.Errorf("%d timers waiting, want the next autosave", len(h.timers))
+	}
+}
+
+func TestAutosaveInFooter(t *testing.T) {
+	h := blankSheet(t)
+	h.Key(tea.KeyCtrlF)
+	h.Type("12")
+	h.Send(autosaveMsg(time.Now()))
+	if h.m.footManual[0] || h.m.footer[0] != "" {
+		t.Errorf("autosave committed the footer: %q, manual %v", h.m.footer[0], h.m.footManual[0])
+	}
+	c, err := parser.ParseHTML("census.autosave.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Footer[0] != "12" {
+		t.Errorf("backup footer %q, want 12", c.Footer[0])
+	}
+}
@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// censusNights gives the night each census was taken, for saying which side
+// of a birthday an age fell.
+var censusNights = map[int]string{
+	1841: "6 June", 1851: "30 March", 1861: "7 April", 1871: "2 April", 1881: "3 April",
+	1891: "5 April", 1901: "31 March", 1911: "2 April", 1921: "19 June",
+}
+
+var (
+	bornAt  = regexp.MustCompile(`(?i)^(?:b\.?|born)\s*(\d{3,4})\s*,?\s*(?:census|in|at)?\s*(\d{3,4})$`)
+	agedAt  = regexp.MustCompile(`(?i)^(?:age|aged)\s*(\d{1,3})\s*,?\s*(?:census|in|at)?\s*(\d{3,4})$`)
+	sumTerm = regexp.MustCompile(`^\s*([+-]?)\s*(\d+)`)
+	errCalc = errors.New(`try "1861 - 34", "b. 1827, census 1861" or "age 34, census 1861"`)
+)
+
+// calc evaluates one line of the age calculator: a sum of whole numbers, the
+// age of someone born in a year at a later year, or the birth year of
+// someone of an age at a year. Without the day of birth an age or birth year
+// is one of two, and both are given.
+func calc(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil
+	}
+	if g := bornAt.FindStringSubmatch(s); g != nil {
+		born, at := atoi(g[1]), atoi(g[2])
+		if at < born {
+			return "", fmt.Errorf("%d is before the birth year %d", at, born)
+		}
+		return fmt.Sprintf("aged %s %s", either(at-born-1, at-born), yearOf(at)), nil
+	}
+	if g := agedAt.FindStringSubmatch(s); g != nil {
+		age, at := atoi(g[1]), atoi(g[2])
+		return fmt.Sprintf("born %s, for age %d %s", either(at-age-1, at-age), age, yearOf(at)), nil
+	}
+
+	total, rest := 0, s
+	for n := 0; rest != ""; n++ {
+		g := sumTerm.FindStringSubmatch(rest)
+		if g == nil || (n > 0 && g[1] == "") {
+			return "", errCalc
+		}
+		v := atoi(g[2])
+		if g[1] == "-" {
+			v = -v
+		}
+		total += v
+		rest = strings.TrimSpace(rest[len(g[0]):])
+	}
+	return strconv.Itoa(total), nil
+}
+
+// either names two consecutive results, dropping one below zero.
+func either(a, b int) string {
+	if a < 0 {
+		return strconv.Itoa(b)
+	}
+	return fmt.Sprintf("%d or %d", a, b)
+}
+
+// yearOf names year y, with the census night when it was a census year.
+func yearOf(y int) string {
+	if night, ok := censusNights[y]; ok {
+		return fmt.Sprintf("at the %d census (%s)", y, night)
+	}
+	return fmt.Sprintf("in %d", y)
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// openCalc shows the age calculator over the editor. It never touches the
+// sheet, so the editor carries on where it was when the calculator closes.
+func (m *model) openCalc() {
+	m.commitCurrent()
+	m.prevMode, m.mode = m.mode, modeCalc
+	if m.calcIn.Placeholder == "" {
+		m.calcIn = newInput("1861 - 34")
+	}
+	m.calcIn.Focus()
+}
+
+func (m model) updateCalc(msg tea.Msg) (tea.Model, tea.Cmd) {
+	km, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch {
+	case km.Type == tea.KeyCtrlC:
+		return m.quit()
+	case km.Type == tea.KeyEsc || m.keys[km.String()] == "calculator":
+		m.calcIn.Blur()
+		m.mode = m.prevMode
+		m.loadCurrent()
+		return m, nil
+	}
+	m.calcIn, _ = m.calcIn.Update(km)
+	return m, nil
+}
+
+func (m model) viewCalc() string {
+	var b bytes.Buffer
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render("Age calculator:") + "\n\n")
+	b.WriteString(lipgloss.NewStyle().Padding(0, 1).Render("=") + m.calcIn.View() + "\n\n")
+	if res, err := calc(m.calcIn.Value()); err != nil {
+		b.WriteString(lipgloss.NewStyle().Faint(true).Render(err.Error()))
+	} else {
+		b.WriteString(lipgloss.NewStyle().Bold(true).Render(res))
+	}
+	b.WriteString("\n\n(Esc to go back to the sheet)")
+	return b.String()
+}
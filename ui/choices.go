@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// choiceKey returns the choice Alt-1 to Alt-9 ticks, counting from 0.
+func choiceKey(k tea.KeyMsg) (int, bool) {
+	if !k.Alt || len(k.Runes) != 1 || k.Runes[0] < '1' || k.Runes[0] > '9' {
+		return 0, false
+	}
+	return int(k.Runes[0] - '1'), true
+}
+
+// toggleChoice ticks or unticks choice i of the focused field, rewriting
+// the cell in the schema's spelling. Text naming none of the choices is kept
+// after them.
+func (m *model) toggleChoice(i int) {
+	in := &m.bodyIn[m.currCol]
+	on, rest := m.schema.SplitChoices(m.currCol, in.Value())
+	on[i] = !on[i]
+	in.SetValue(m.schema.JoinChoices(m.currCol, on, rest))
+	in.CursorEnd()
+}
+
+// choicesLine shows the ticks in the focused field, for fields that have a
+// fixed set of choices, or "".
+func (m *model) choicesLine() string {
+	choices := m.schema.Choices[m.currCol]
+	if len(choices) == 0 {
+		return ""
+	}
+	on, rest := m.schema.SplitChoices(m.currCol, m.bodyIn[m.currCol].Value())
+	var parts []string
+	for i, c := range choices {
+		box := "[ ]"
+		if on[i] {
+			box = "[x]"
+		}
+		parts = append(parts, fmt.Sprintf("Alt-%d %s %s", i+1, box, c))
+	}
+	line := strings.Join(parts, "  ")
+	if len(rest) > 0 {
+		line += "  + " + strings.Join(rest, ", ")
+	}
+	return lipgloss.NewStyle().Faint(true).Render(line)
+}
+
+// cycleValue replaces the focused field with the next of its usual values
+// written in full, then blank, then the first again. A value written
+// another way, such as "Mar." for Married, moves on from the word it
+// shortens; anything else starts from the first.
+func (m *model) cycleValue() {
+	words := m.schema.Words(m.currCol)
+	if len(words) == 0 {
+		m.notice = m.schema.Labels[m.currCol] + " has no usual values to step through"
+		return
+	}
+	in := &m.bodyIn[m.currCol]
+	v := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(in.Value()), "."))
+	next := words[0]
+	for i, w := range words {
+		if v != "" && strings.HasPrefix(strings.ToLower(w), v) {
+			next = ""
+			if i+1 < len(words) {
+				next = words[i+1]
+			}
+			break
+		}
+	}
+	in.SetValue(next)
+	in.CursorEnd()
+}
@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/lipgloss"
+
+	"testme/parser"
+)
+
+// readClipboard reads the system clipboard as clipText does.
+func readClipboard() (parser.Census, [][]string, error) {
+	text, err := clipboard.ReadAll()
+	if err != nil {
+		return parser.Census{}, nil, err
+	}
+	return clipText(text)
+}
+
+// clipText reads pasted text. Census HTML, when it looks like markup, comes
+// back as a page; tab-separated text as its records, and comma-separated
+// text too when it runs to several lines of the same number of fields, so a
+// stray comma in "Smith, John" is not taken for a table.
+func clipText(text string) (parser.Census, [][]string, error) {
+	if strings.TrimSpace(text) == "" {
+		return parser.Census{}, nil, errors.New("clipboard is empty")
+	}
+
+	lower := strings.ToLower(text)
+	switch {
+	case strings.Contains(lower, "<td") || strings.Contains(lower, "<th"):
+		c, err := parser.ParseReader(strings.NewReader(text))
+		if err == nil && parser.BlankRows(c.Rows) && c.HeaderMatched == [parser.HeadCount]bool{} {
+			err = errors.New("clipboard HTML holds no census table")
+		}
+		return c, nil, err
+	case strings.Contains(text, "\t"):
+		recs, err := parser.ReadTable(strings.NewReader(text), '\t')
+		return parser.Census{}, recs, err
+	case strings.Contains(text, ","):
+		recs, err := parser.ReadTable(strings.NewReader(text), ',')
+		if err == nil && evenRecords(recs) {
+			return parser.Census{}, recs, nil
+		}
+	}
+	return parser.Census{}, nil, errors.New("clipboard is neither HTML nor tabular text")
+}
+
+// evenRecords reports whether recs are two or more records of the same
+// number of fields, more than one each.
+func evenRecords(recs [][]string) bool {
+	if len(recs) < 2 || len(recs[0]) < 2 {
+		return false
+	}
+	for _, r := range recs[1:] {
+		if len(r) != len(recs[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// paste takes in the clipboard. In body mode a spreadsheet block fills the
+// grid from the focused cell; anything else replaces the whole form, after
+// asking when the sheet is not blank.
+func (m *model) paste() {
+	c, recs, err := readClipboard()
+	m.takePaste(c, recs, err)
+}
+
+// takePaste applies what readClipboard returned.
+func (m *model) takePaste(c parser.Census, recs [][]string, err error) {
+	switch {
+	case err != nil:
+	case recs != nil && m.mode == modeBody:
+		m.pasteCells(recs)
+		return
+	case recs != nil:
+		c, err = parser.TableCensus(recs, m.schema)
+	}
+	if err != nil {
+		m.notice = lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render("✗ clipboard: " + err.Error())
+		return
+	}
+	if !m.liveSnap().sameData(snapshot{rows: parser.PadRows(nil)}) {
+		m.pendingPaste, m.confirmPaste = c, true
+		return
+	}
+	m.loadCensus(c)
+	m.justRead = true
+}
+
+// pasteCells copies a spreadsheet block into the body from the focused cell:
+// each record fills a row and its values the fields from there on, in form
+// order, blanks included. Pages are added for records running past the last
+// row, up to parser.MaxRows; records past that and values beyond the last
+// field are dropped and counted in the notice. The paste undoes as one step.
+func (m *model) pasteCells(recs [][]string) {
+	m.commitCurrent()
+	m.checkpoint()
+	start, dropped, cut := m.schema.Pos(m.currCol), 0, 0
+	if extra := m.currRow + len(recs) - parser.MaxRows; extra > 0 {
+		recs, cut = recs[:len(recs)-extra], extra
+	}
+	if n := m.currRow + len(recs); n > len(m.rows) {
+		m.rows = parser.PadRows(append(m.rows, make([]Row, n-len(m.rows))...))
+	}
+	for i, rec := range recs {
+		ri := m.currRow + i
+		for j, v := range rec {
+			pos := start + j
+			if pos >= len(m.schema.Columns) {
+				if v != "" {
+					dropped++
+				}
+				continue
+			}
+			m.rows[ri].Col[m.schema.Columns[pos]] = v
+		}
+	}
+	m.loadCurrent()
+	m.notice = fmt.Sprintf("pasted %d row(s)", len(recs))
+	warn := lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+	if dropped > 0 {
+		m.notice += warn.Render(fmt.Sprintf(" — %d value(s) did not fit on the sheet and were left out", dropped))
+	}
+	if cut > 0 {
+		m.notice += warn.Render(fmt.Sprintf(" — %d record(s) ran past the %d rows a sheet may hold and were left out", cut, parser.MaxRows))
+	}
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"testme/parser"
+	"testme/schema"
+)
+
+func TestClipText(t *testing.T) {
+	for _, tc := range []struct {
+		text  string
+		table bool
+	}{
+		{"Smith, John", false},
+		{"Smith, John\nJones", false},
+		{"1,High St\n2,Low St\n", true},
+		{"1\tHigh St", true},
+		{"just words", false},
+	} {
+		_, recs, err := clipText(tc.text)
+		if tc.table && (err != nil || recs == nil) {
+			t.Errorf("%q: got %v, %v; want a table", tc.text, recs, err)
+		}
+		if !tc.table && err == nil {
+			t.Errorf("%q: taken as %q; want an error", tc.text, recs)
+		}
+	}
+}
+
+func TestPasteAsksBeforeReplacing(t *testing.T) {
+	h := blankSheet(t)
+	h.Type("Great Canfield")
+	c, recs, err := clipText("1,High St\n2,Low St")
+	h.m.takePaste(c, recs, err)
+	parish := func() string { return h.m.liveSnap().header[0] }
+	if !h.m.confirmPaste || parish() != "Great Canfield" {
+		t.Fatalf("pasted over a filled sheet without asking: parish %q", parish())
+	}
+	if !strings.Contains(h.View(), "Replace the whole sheet") {
+		t.Errorf("no prompt shown:\n%s", h.View())
+	}
+	h.Key(tea.KeyEsc)
+	if h.m.confirmPaste || parish() != "Great Canfield" {
+		t.Errorf("another key did not keep the sheet: parish %q", parish())
+	}
+
+	h.m.takePaste(c, recs, err)
+	h.Alt('v')
+	if parish() != "" || h.m.rows[1].Col[schema.Address] != "Low St" {
+		t.Errorf("Alt-V again did not paste: parish %q, row 2 %q", parish(), h.m.rows[1].Col)
+	}
+}
+
+func TestPasteCellsStopsAtMaxRows(t *testing.T) {
+	h := blankSheet(t)
+	h.Key(tea.KeyCtrlB)
+	recs := make([][]string, parser.MaxRows+3)
+	for i := range recs {
+		recs[i] = []string{strconv.Itoa(i + 1)}
+	}
+	h.m.takePaste(parser.Census{}, recs, nil)
+	if len(h.m.rows) != parser.MaxRows {
+		t.Errorf("sheet holds %d rows after the paste, want %d", len(h.m.rows), parser.MaxRows)
+	}
+	if v := h.m.rows[parser.MaxRows-1].Col[schema.Sched]; v != strconv.Itoa(parser.MaxRows) {
+		t.Errorf("last row's Sched# %q, want %d", v, parser.MaxRows)
+	}
+	if !strings.Contains(h.m.notice, "3 record(s) ran past") {
+		t.Errorf("notice %q does not report the records left out", h.m.notice)
+	}
+}
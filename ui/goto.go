@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openGoto asks above the body for a row number to move to. It only moves
+// the focus, so nothing on the sheet changes.
+func (m *model) openGoto() {
+	m.gotoIn = newInput(fmt.Sprintf("1-%d", len(m.rows)))
+	m.gotoIn.CharLimit = 6
+	m.gotoIn.Focus()
+	m.goingTo = true
+}
+
+// updateGoto takes digits into the prompt; Enter moves to the row and Esc
+// closes it where the focus was.
+func (m model) updateGoto(km tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch km.Type {
+	case tea.KeyCtrlC:
+		return m.quit()
+	case tea.KeyEsc:
+		m.goingTo = false
+	case tea.KeyEnter:
+		m.goingTo = false
+		v := strings.TrimSpace(m.gotoIn.Value())
+		if v == "" {
+			break
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			m.notice = fmt.Sprintf("%q is not a row number", v)
+			break
+		}
+		m.goToRow(n)
+	case tea.KeyRunes:
+		km.Runes = []rune(strings.Map(func(r rune) rune {
+			if r < '0' || r > '9' {
+				return -1
+			}
+			return r
+		}, string(km.Runes)))
+		fallthrough
+	default:
+		m.gotoIn, _ = m.gotoIn.Update(km)
+	}
+	return m, nil
+}
+
+// goToRow moves to row n, counted from 1, or to the first or last row when n
+// is outside the sheet.
+func (m *model) goToRow(n int) {
+	m.commitCurrent()
+	m.currRow = min(max(n, 1), len(m.rows)) - 1
+	m.loadCurrent()
+}
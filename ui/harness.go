@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"testme/config"
+	"testme/parser"
+)
+
+// Harness drives the editor without a terminal, for integration tests: feed
+// it key messages and inspect the sheet, the focus and the rendered view.
+// It starts from default settings and keys and never writes the config file.
+// Saving writes census.html in the working directory, so tests exercising
+// Ctrl-W should run from a temporary one.
+//
+// Timers, such as the one taking down a page's notes or the next autosave,
+// do not run by themselves: they wait until Tick fires them.
+type Harness struct {
+	m      model
+	timers []func(time.Time) tea.Msg
+}
+
+// timerMsg is a timer the editor set, held back for Tick.
+type timerMsg func(time.Time) tea.Msg
+
+// NewHarness returns a harness at the year menu with default settings.
+func NewHarness() *Harness {
+	m := NewModel()
+	m.cfg, m.ephemeral, m.offerRecovery = config.Config{}, true, false
+	m.years, m.yearIdx, _ = m.cfg.YearMenu()
+	m.setKeymap(config.DefaultKeymap)
+	m.applySchema()
+	m.tick = func(_ time.Duration, fn func(time.Time) tea.Msg) tea.Cmd {
+		return func() tea.Msg { return timerMsg(fn) }
+	}
+	return &Harness{m: m}
+}
+
+// Config returns the settings in use, for tests to change before sending keys.
+func (h *Harness) Config() *config.Config { return &h.m.cfg }
+
+// Send passes each message to Update in turn and reports whether the
+// program asked to quit. The commands returned are run and their messages
+// fed back, so file picker listings arrive as they would live; batches are
+// run one command at a time, and timers are kept for Tick.
+func (h *Harness) Send(msgs ...tea.Msg) (quit bool) {
+	for _, msg := range msgs {
+		next, cmd := h.m.Update(msg)
+		h.m = next.(model)
+		if h.run(cmd) {
+			return true
+		}
+	}
+	return false
+}
+
+// run runs cmd as the program would, reporting whether it quits.
+func (h *Harness) run(cmd tea.Cmd) (quit bool) {
+	if cmd == nil {
+		return false
+	}
+	switch out := cmd().(type) {
+	case nil:
+	case tea.QuitMsg:
+		return true
+	case tea.BatchMsg:
+		for _, c := range out {
+			if h.run(c) {
+				return true
+			}
+		}
+	case timerMsg:
+		h.timers = append(h.timers, out)
+	default:
+		return h.Send(out)
+	}
+	return false
+}
+
+// Tick fires the timers set so far, as though their time had come, and
+// reports whether the program asked to quit. Timers they set in turn wait
+// for the next Tick.
+func (h *Harness) Tick() (quit bool) {
+	due := h.timers
+	h.timers = nil
+	for _, fn := range due {
+		if h.Send(fn(time.Now())) {
+			return true
+		}
+	}
+	return false
+}
+
+// Init runs the editor's start-up commands, as the program does once before
+// the first key.
+func (h *Harness) Init() (quit bool) { return h.run(h.m.Init()) }
+
+// Key sends keys of the given types, such as tea.KeyTab or tea.KeyCtrlW.
+func (h *Harness) Key(keys ...tea.KeyType) (quit bool) {
+	for _, k := range keys {
+		if h.Send(tea.KeyMsg{Type: k}) {
+			return true
+		}
+	}
+	return false
+}
+
+// Type sends s as typed characters to the focused input.
+func (h *Harness) Type(s string) {
+	for _, r := range s {
+		h.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+// Alt sends the Alt-modified letter key, such as 'n' for Alt-N.
+func (h *Harness) Alt(r rune) {
+	h.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}, Alt: true})
+}
+
+// Census returns the sheet as it would be exported, including what is typed
+// into the body row being edited.
+func (h *Harness) Census() parser.Census {
+	c := h.m.census()
+	c.Rows = parser.TrimPages(h.m.liveRows())
+	return c
+}
+
+// Mode returns the name of the editing mode: YEAR, HEADER, BODY, FOOTER, or
+// "" for the other screens.
+func (h *Harness) Mode() string {
+	if int(h.m.mode) < len(modeNames) {
+		return modeNames[h.m.mode]
+	}
+	return ""
+}
+
+// Focus returns the focused body row (0-based) and field. In body mode the
+// field is a logical schema field; elsewhere it is the input's position.
+func (h *Harness) Focus() (row, col int) { return h.m.currRow, h.m.currCol }
+
+// View renders the current screen.
+func (h *Harness) View() string { return h.m.View() }
@@ -0,0 +1,206 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"testme/parser"
+	"testme/schema"
+)
+
+// blankSheet starts the editor on a blank 1861 page, with the working
+// directory a temporary one so saves land there.
+func blankSheet(t *testing.T) *Harness {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	h := NewHarness()
+	h.Key(tea.KeyEnter)
+	if h.Mode() != "HEADER" {
+		t.Fatalf("after picking the year: mode %q, want HEADER", h.Mode())
+	}
+	return h
+}
+
+// bodyRow starts a blank page and types vals into the first body row, a
+// field at a time.
+func bodyRow(t *testing.T, vals ...string) *Harness {
+	t.Helper()
+	h := blankSheet(t)
+	h.Key(tea.KeyCtrlB)
+	for i, v := range vals {
+		if i > 0 {
+			h.Key(tea.KeyTab)
+		}
+		h.Type(v)
+	}
+	return h
+}
+
+func TestBodyTyping(t *testing.T) {
+	h := bodyRow(t, "1", "High Street", "1", "", "John Smith")
+	if h.Mode() != "BODY" {
+		t.Fatalf("mode %q, want BODY", h.Mode())
+	}
+	if row, col := h.Focus(); row != 0 || col != schema.Name {
+		t.Errorf("focus (%d, %d), want (0, %d)", row, col, schema.Name)
+	}
+	got := h.Census().Rows[0].Col
+	want := map[int]string{schema.Sched: "1", schema.Address: "High Street", schema.Inhabited: "1", schema.Name: "John Smith"}
+	for f, v := range want {
+		if got[f] != v {
+			t.Errorf("%s = %q, want %q", schema.Keys[f], got[f], v)
+		}
+	}
+}
+
+func TestTabKeepsRowUntilLeft(t *testing.T) {
+	h := bodyRow(t, "1")
+	h.Key(tea.KeyTab)
+	if _, col := h.Focus(); col != schema.Address {
+		t.Errorf("after Tab, focus on field %d, want %d", col, schema.Address)
+	}
+	if v := h.Census().Rows[0].Col[schema.Sched]; v != "1" {
+		t.Errorf("after Tab, Sched# reads %q, want 1", v)
+	}
+	if v := h.m.rows[0].Col[schema.Sched]; v != "" {
+		t.Errorf("row committed as %q before leaving it", v)
+	}
+	h.Key(tea.KeyDown)
+	if v := h.m.rows[0].Col[schema.Sched]; v != "1" {
+		t.Errorf("after leaving the row, Sched# = %q, want 1", v)
+	}
+}
+
+func TestEnterOnLastFieldCommitsRow(t *testing.T) {
+	h := bodyRow(t, "1")
+	h.Key(tea.KeyEnter)
+	if row, col := h.Focus(); row != 0 || col != schema.Address {
+		t.Errorf("after Enter, focus (%d, %d), want (0, %d)", row, col, schema.Address)
+	}
+
+	cols := schema.For("1861").Columns
+	for range len(cols) - 2 {
+		h.Key(tea.KeyTab)
+	}
+	h.Type("Deaf")
+	h.Key(tea.KeyEnter)
+	if row, col := h.Focus(); row != 1 || col != cols[0] {
+		t.Errorf("after Enter on the last field, focus (%d, %d), want (1, %d)", row, col, cols[0])
+	}
+	got := h.m.rows[0].Col
+	if got[schema.Sched] != "1" || got[cols[len(cols)-1]] != "Deaf" {
+		t.Errorf("row 1 committed as %q", got)
+	}
+}
+
+func TestClearRow(t *testing.T) {
+	h := bodyRow(t, "1", "High Street", "1", "", "John Smith")
+	h.Key(tea.KeyDown)
+	h.Type("Jane")
+	h.Key(tea.KeyUp)
+	h.Key(tea.KeyCtrlN)
+	c := h.Census()
+	if c.Rows[0] != (parser.Row{}) {
+		t.Errorf("row 1 after Ctrl-N: %q", c.Rows[0].Col)
+	}
+	if v := c.Rows[1].Col[schema.Name]; v != "Jane" {
+		t.Errorf("Ctrl-N on row 1 changed row 2's name to %q", v)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	h := bodyRow(t, "1", "High Street", "1", "", "John Smith")
+	if !strings.Contains(h.View(), "Census TUI *") {
+		t.Errorf("title does not mark the unsaved change:\n%s", h.View())
+	}
+	h.Key(tea.KeyCtrlW)
+	c, err := parser.ParseHTML("census.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := c.Rows[0].Col[schema.Name]; v != "John Smith" {
+		t.Errorf("census.html has name %q, want John Smith", v)
+	}
+	if h.m.dirty() {
+		t.Error("sheet still counts as changed after Ctrl-W")
+	}
+}
+
+func TestNotesWaitForTick(t *testing.T) {
+	h := NewHarness()
+	dir := t.TempDir()
+	page := filepath.Join(dir, "page.html")
+	src := `<table><tr><th>Parish<br>Great Canfield</th><th>City<br></th></tr></table>`
+	if err := os.WriteFile(page, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	h.Config().Recent = []string{page}
+	h.Key(tea.KeyEnter, tea.KeyDown, tea.KeyEnter)
+	if h.m.header[0] != "Great Canfield" {
+		t.Fatalf("parish %q after opening %s", h.m.header[0], page)
+	}
+	if !strings.Contains(h.View(), "found 2 header fields, expected 7") {
+		t.Errorf("note missing after opening the page:\n%s", h.View())
+	}
+	if len(h.timers) != 1 {
+		t.Errorf("%d timers waiting, want the notes timer", len(h.timers))
+	}
+	h.Tick()
+	if len(h.timers) != 0 {
+		t.Errorf("%d timers still waiting after Tick", len(h.timers))
+	}
+}
+
+func TestSaveAndQuitKeepsTotalsInStep(t *testing.T) {
+	h := bodyRow(t, "1", "High Street", "1", "", "John Smith", "Head", "Mar", "40")
+	h.Config().AutoTotals = true
+	if h.Key(tea.KeyEsc) {
+		t.Fatal("quit with unsaved changes without asking")
+	}
+	if !h.Key(tea.KeyCtrlW) {
+		t.Fatal("Ctrl-W at the prompt did not quit")
+	}
+	c, err := parser.ParseHTML("census.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Footer[2] != "1" {
+		t.Errorf("saved Total Males %q, want 1 as Ctrl-W alone would write", c.Footer[2])
+	}
+}
+
+func TestScheduleOfferedNotWritten(t *testing.T) {
+	h := bodyRow(t, "1")
+	h.Config().AutoSchedule = true
+	h.Key(tea.KeyDown)
+	if !strings.Contains(h.View(), "→ 2") {
+		t.Errorf("next schedule number not offered on row 2:\n%s", h.View())
+	}
+	h.Key(tea.KeyDown)
+	if v := h.m.rows[1].Col[schema.Sched]; v != "" {
+		t.Errorf("passing through row 2 wrote Sched# %q", v)
+	}
+	if h.m.rows[1] != (Row{}) || countPeople(h.m.rows) != 0 {
+		t.Errorf("row 2 no longer blank: %q", h.m.rows[1].Col)
+	}
+	h.Key(tea.KeyUp, tea.KeyRight)
+	if v := h.Census().Rows[1].Col[schema.Sched]; v != "2" {
+		t.Errorf("→ took Sched# %q, want 2", v)
+	}
+}
+
+func TestFillDownIn1841(t *testing.T) {
+	h := blankSheet(t)
+	h.m.setYear("1841")
+	h.Config().FillDown = true
+	h.Key(tea.KeyCtrlB)
+	h.Type("Great Canfield")
+	h.Key(tea.KeyDown)
+	if got := h.Census().Rows[1].Col[schema.Address]; got != "Great Canfield" {
+		t.Errorf("row 2 place %q, want it carried down", got)
+	}
+}
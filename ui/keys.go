@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"strings"
+
+	"testme/config"
+)
+
+// setKeymap installs km as the editing keys.
+func (m *model) setKeymap(km config.Keymap) {
+	m.keymap, m.keys = km, map[string]string{}
+	for a, k := range km {
+		m.keys[k] = a
+	}
+}
+
+// keyName returns the key bound to action for display, e.g. "Ctrl‑W".
+func (m *model) keyName(action string) string {
+	return prettyKey(m.keymap[action])
+}
+
+// prettyKey turns a Bubble Tea key name such as "ctrl+w" into "Ctrl‑W". The
+// hyphen is non-breaking so hints do not wrap inside a key.
+func prettyKey(k string) string {
+	switch k {
+	case "":
+		return "unbound"
+	case "ctrl+@":
+		return "Ctrl‑Space"
+	case "ctrl+_":
+		return "Ctrl‑/"
+	}
+	parts := strings.Split(k, "+")
+	for i, p := range parts {
+		switch {
+		case p == " ":
+			parts[i] = "Space"
+		case len(p) == 1:
+			parts[i] = strings.ToUpper(p)
+		default:
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, "‑")
+}
+
+// titleHints lists the actions summarised in the title bar.
+var titleHints = []struct{ action, label string }{
+	{"header", "header"}, {"body", "body"}, {"footer", "footer"},
+	{"clear", "clear"}, {"ref", "ref"}, {"undo", "undo"}, {"redo", "redo"},
+	{"open", "open"}, {"write", "write"}, {"export-all", "export all"},
+	{"settings", "settings"}, {"quit", "quit"},
+}
+
+// keyHints is the key summary shown in the title bar when it fits.
+func (m *model) keyHints() string {
+	hints := []string{"↑↓ • Tab/Shift‑Tab"}
+	for _, h := range titleHints {
+		hints = append(hints, m.keyName(h.action)+" "+h.label)
+	}
+	return "(" + strings.Join(hints, " • ") + ")"
+}
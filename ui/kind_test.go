@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"testme/parser"
+	"testme/schema"
+)
+
+func TestCycleKindDropsReadID(t *testing.T) {
+	h := blankSheet(t)
+	h.m.rows[0].Col[schema.Name] = "John Smith"
+	h.m.rows[0].Ref[schema.Name] = "dpR1C5"
+	h.m.rows[0].Ref[schema.Relation] = "R1C6"
+	h.Key(tea.KeyCtrlB)
+	for h.m.currCol != schema.Name {
+		h.Key(tea.KeyTab)
+	}
+	h.Key(tea.KeyCtrlR)
+	r := h.m.rows[0]
+	if k := r.KindOf(h.m.schema, schema.Name); k != parser.RefPlace {
+		t.Fatalf("markup %v after Ctrl-R, want PlaceRef", k)
+	}
+	if r.Ref[schema.Name] != "" {
+		t.Errorf("PlaceRef keeps the PersonRef id %q", r.Ref[schema.Name])
+	}
+	if r.Ref[schema.Relation] != "R1C6" {
+		t.Errorf("the next cell's id became %q", r.Ref[schema.Relation])
+	}
+}
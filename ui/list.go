@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+
+	"testme/schema"
+)
+
+// listFields are the columns of the row list, where the year's form has them.
+var listFields = []int{schema.Sched, schema.Name, schema.Relation, schema.AgeMale, schema.Occupation}
+
+// openList shows every filled row as one line, to look the sheet over
+// before saving. It only reads the sheet.
+func (m *model) openList() {
+	m.commitCurrent()
+	m.listIn = newInput("type to search")
+	m.listIn.Width = 30
+	m.listIn.Focus()
+	m.prevMode, m.mode = m.mode, modeList
+	m.listCur = 0
+	for i, r := range m.listRows() {
+		if r == m.currRow {
+			m.listCur = i
+		}
+	}
+}
+
+// listRows returns the filled rows, or while searching those with a cell
+// holding the search text, ignoring case.
+func (m *model) listRows() []int {
+	q := strings.ToLower(strings.TrimSpace(m.listIn.Value()))
+	var rows []int
+	for ri, r := range m.rows {
+		match := false
+		for _, c := range m.schema.Columns {
+			if r.Col[c] != "" && strings.Contains(strings.ToLower(r.Col[c]), q) {
+				match = true
+				break
+			}
+		}
+		if match {
+			rows = append(rows, ri)
+		}
+	}
+	return rows
+}
+
+func (m model) updateList(km tea.KeyMsg) (tea.Model, tea.Cmd) {
+	rows := m.listRows()
+	switch {
+	case km.Type == tea.KeyCtrlC:
+		return m.quit()
+	case km.Type == tea.KeyEsc || m.keys[km.String()] == "list":
+		m.mode = m.prevMode
+		m.loadCurrent()
+	case km.Type == tea.KeyUp:
+		m.listCur = max(m.listCur-1, 0)
+	case km.Type == tea.KeyDown:
+		m.listCur = min(m.listCur+1, max(len(rows)-1, 0))
+	case km.Type == tea.KeyEnter:
+		if len(rows) == 0 {
+			break
+		}
+		m.currRow = rows[m.listCur]
+		m.switchMode(modeBody)
+	default:
+		m.listIn, _ = m.listIn.Update(km)
+		m.listCur = 0
+	}
+	return m, nil
+}
+
+// listHeight is how many rows the list shows at once.
+func (m *model) listHeight() int {
+	if m.height == 0 {
+		return 20
+	}
+	return max(m.height-8, 3)
+}
+
+// viewList draws the rows found, scrolled to keep the cursor in sight.
+func (m model) viewList() string {
+	var fields []int
+	for _, f := range listFields {
+		if m.schema.Has(f) {
+			fields = append(fields, f)
+		}
+	}
+	widths := map[int]int{schema.Sched: 8, schema.Name: 28, schema.Relation: 12, schema.AgeMale: 6, schema.Occupation: 28}
+	cell := func(s string, w int) string { return runewidth.FillRight(runewidth.Truncate(s, w, "…"), w) }
+	line := func(row string, vals map[int]string) string {
+		parts := []string{cell(row, 4)}
+		for _, f := range fields {
+			parts = append(parts, cell(vals[f], widths[f]))
+		}
+		return strings.Join(parts, " ")
+	}
+	faint := lipgloss.NewStyle().Faint(true)
+
+	var b bytes.Buffer
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render("Rows on the sheet:") + "  " + m.listIn.View() + "\n\n")
+	heads := map[int]string{}
+	for _, f := range fields {
+		heads[f] = m.schema.Labels[f]
+	}
+	heads[schema.AgeMale] = "Age"
+	b.WriteString(faint.Render(line("Row", heads)) + "\n")
+
+	rows := m.listRows()
+	h := m.listHeight()
+	first := min(max(m.listCur-h/2, 0), max(len(rows)-h, 0))
+	for i := first; i < min(first+h, len(rows)); i++ {
+		r := m.rows[rows[i]]
+		vals := map[int]string{}
+		for _, f := range fields {
+			vals[f] = r.Col[f]
+		}
+		switch {
+		case r.Col[schema.AgeMale] != "":
+			vals[schema.AgeMale] = r.Col[schema.AgeMale] + " ♂"
+		case r.Col[schema.AgeFemale] != "":
+			vals[schema.AgeMale] = r.Col[schema.AgeFemale] + " ♀"
+		}
+		l := line(fmt.Sprint(rows[i]+1), vals)
+		if i == m.listCur {
+			l = lipgloss.NewStyle().Reverse(true).Render(l)
+		}
+		b.WriteString(l + "\n")
+	}
+	if len(rows) == 0 {
+		b.WriteString(faint.Render("no rows found") + "\n")
+	}
+	b.WriteString(faint.Render(fmt.Sprintf("\n%d row(s) • ↑/↓ to move, Enter to edit the row, %s or Esc to go back", len(rows), m.keyName("list"))))
+	return b.String()
+}
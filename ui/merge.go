@@ -0,0 +1,222 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"testme/schema"
+)
+
+// placeGroup is a set of birthplace spellings taken for one place. pick is
+// the spelling the others become, and skip leaves a spelling out.
+type placeGroup struct {
+	variants []string // most used first
+	counts   []int
+	pick     int
+	skip     []bool
+}
+
+// placeWords splits a birthplace into lower-case words, dropping
+// punctuation, so "Leeds, Yorks." is leeds yorks.
+func placeWords(v string) []string {
+	return strings.FieldsFunc(strings.ToLower(v), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// placeGroups groups the distinct birthplaces of rows whose words are the
+// same, or where one's words begin the other's ("Leeds", "Leeds, Yorks",
+// "Leeds Yorkshire"). Places written only one way are left out.
+func placeGroups(rows []Row) []placeGroup {
+	var names []string
+	count := map[string]int{}
+	for _, r := range rows {
+		v := strings.TrimSpace(r.Col[schema.Birthplace])
+		if v == "" {
+			continue
+		}
+		if count[v] == 0 {
+			names = append(names, v)
+		}
+		count[v]++
+	}
+
+	// join each name to the first earlier one it matches, then follow the
+	// links to the group's first name
+	parent := make([]int, len(names))
+	root := func(i int) int {
+		for parent[i] != i {
+			i = parent[i]
+		}
+		return i
+	}
+	for i, n := range names {
+		parent[i] = i
+		for j := range i {
+			a, b := placeWords(n), placeWords(names[j])
+			if len(a) > len(b) {
+				a, b = b, a
+			}
+			if len(a) > 0 && slices.Equal(a, b[:len(a)]) {
+				parent[root(i)] = root(j)
+			}
+		}
+	}
+	byRoot := map[int]*placeGroup{}
+	var groups []*placeGroup
+	for i, n := range names {
+		g, ok := byRoot[root(i)]
+		if !ok {
+			g = &placeGroup{}
+			byRoot[root(i)] = g
+			groups = append(groups, g)
+		}
+		g.variants = append(g.variants, n)
+	}
+
+	var out []placeGroup
+	for _, g := range groups {
+		if len(g.variants) < 2 {
+			continue
+		}
+		slices.SortStableFunc(g.variants, func(a, b string) int { return count[b] - count[a] })
+		for _, v := range g.variants {
+			g.counts = append(g.counts, count[v])
+		}
+		g.skip = make([]bool, len(g.variants))
+		out = append(out, *g)
+	}
+	slices.SortFunc(out, func(a, b placeGroup) int { return strings.Compare(a.variants[0], b.variants[0]) })
+	return out
+}
+
+// mergeEdits returns the cells merging g would change: every birthplace
+// spelled as one of its variants, unless skipped, becomes the picked one.
+func mergeEdits(rows []Row, g placeGroup) []cellEdit {
+	to := g.variants[g.pick]
+	var edits []cellEdit
+	for ri, r := range rows {
+		v := strings.TrimSpace(r.Col[schema.Birthplace])
+		if i := slices.Index(g.variants, v); i >= 0 && !g.skip[i] && r.Col[schema.Birthplace] != to {
+			edits = append(edits, cellEdit{ri, schema.Birthplace, r.Col[schema.Birthplace], to})
+		}
+	}
+	return edits
+}
+
+// mergeAt returns the group and variant of flat position i in the list.
+func (m *model) mergeAt(i int) (g, v int) {
+	for g := range m.merge {
+		if i < len(m.merge[g].variants) {
+			return g, i
+		}
+		i -= len(m.merge[g].variants)
+	}
+	return -1, -1
+}
+
+func (m *model) openMerge() {
+	m.commitCurrent()
+	m.merge, m.mergeCur = placeGroups(m.rows[:]), 0
+	if len(m.merge) == 0 {
+		m.notice = "every birthplace on the page is written one way"
+		return
+	}
+	m.prevMode, m.mode = m.mode, modeMerge
+}
+
+func (m model) updateMerge(msg tea.Msg) (tea.Model, tea.Cmd) {
+	km, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	total := 0
+	for _, g := range m.merge {
+		total += len(g.variants)
+	}
+	g, v := m.mergeAt(m.mergeCur)
+	switch km.String() {
+	case "ctrl+c":
+		return m.quit()
+	case "esc":
+		m.mode = m.prevMode
+		m.loadCurrent()
+	case "up":
+		m.mergeCur = (m.mergeCur - 1 + total) % total
+	case "down":
+		m.mergeCur = (m.mergeCur + 1) % total
+	case "tab":
+		m.merge[g].pick, m.merge[g].skip[v] = v, false
+	case " ":
+		if v != m.merge[g].pick {
+			m.merge[g].skip[v] = !m.merge[g].skip[v]
+		}
+	case m.keymap["undo"]:
+		if !m.undo() {
+			m.notice = "nothing to undo"
+		}
+		m.mode = modeMerge
+		if m.merge, m.mergeCur = placeGroups(m.rows[:]), 0; len(m.merge) == 0 {
+			m.mode = m.prevMode
+			m.loadCurrent()
+		}
+	case "enter":
+		edits := mergeEdits(m.rows[:], m.merge[g])
+		if len(edits) > 0 {
+			m.checkpoint()
+			for _, e := range edits {
+				m.rows[e.row].Col[e.col] = e.to
+			}
+		}
+		m.notice = fmt.Sprintf("%d birthplace(s) now read %q", len(edits), m.merge[g].variants[m.merge[g].pick])
+		if m.merge = placeGroups(m.rows[:]); len(m.merge) == 0 {
+			m.mode = m.prevMode
+			m.loadCurrent()
+		} else {
+			m.mergeCur = 0
+		}
+	}
+	return m, nil
+}
+
+func (m model) viewMerge() string {
+	var b bytes.Buffer
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render("Birthplaces written more than one way:") + "\n\n")
+	cur := lipgloss.NewStyle().Reverse(true)
+	faint := lipgloss.NewStyle().Faint(true)
+	i := 0
+	for gi, g := range m.merge {
+		for vi, v := range g.variants {
+			mark := "  "
+			switch {
+			case vi == g.pick:
+				mark = "▸ "
+			case g.skip[vi]:
+				mark = "✗ "
+			}
+			line := fmt.Sprintf("%s%s (%d)", mark, v, g.counts[vi])
+			if i == m.mergeCur {
+				line = cur.Render(line)
+			} else if g.skip[vi] {
+				line = faint.Render(line)
+			}
+			b.WriteString("  " + line + "\n")
+			i++
+		}
+		if gi < len(m.merge)-1 {
+			b.WriteString("\n")
+		}
+	}
+	if g, _ := m.mergeAt(m.mergeCur); g >= 0 {
+		edits := mergeEdits(m.rows[:], m.merge[g])
+		b.WriteString(fmt.Sprintf("\nEnter changes %d cell(s) to %q\n", len(edits), m.merge[g].variants[m.merge[g].pick]))
+	}
+	b.WriteString(faint.Render("\n(↑/↓ to choose, Tab to keep this spelling, Space to leave one out, Enter to merge the group, " + m.keyName("undo") + " to undo, Esc to go back)"))
+	return b.String()
+}
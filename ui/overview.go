@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+
+	"testme/parser"
+)
+
+// openOverview shows the whole sheet as a grid. The editor's position is put
+// aside so the overview cursor can roam without losing it.
+func (m *model) openOverview() {
+	m.commitCurrent()
+	m.editRow, m.editCol = m.currRow, m.currCol
+	if m.mode != modeBody {
+		m.currCol = m.schema.Columns[0]
+	}
+	m.prevMode, m.mode = m.mode, modeOverview
+}
+
+// closeOverview returns to the editor with the focus it had before.
+func (m *model) closeOverview() {
+	m.mode = m.prevMode
+	m.currRow, m.currCol = m.editRow, m.editCol
+	m.loadCurrent()
+}
+
+func (m model) updateOverview(km tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.keys[km.String()] == "overview" || km.Type == tea.KeyEsc {
+		m.closeOverview()
+		return m, nil
+	}
+	cols := m.schema.Columns
+	switch km.Type {
+	case tea.KeyCtrlC:
+		return m.quit()
+	case tea.KeyUp:
+		m.currRow = max(m.currRow-1, 0)
+	case tea.KeyDown:
+		m.currRow = min(m.currRow+1, len(m.rows)-1)
+	case tea.KeyLeft, tea.KeyShiftTab:
+		m.currCol = cols[max(m.schema.Pos(m.currCol)-1, 0)]
+	case tea.KeyRight, tea.KeyTab:
+		m.currCol = cols[min(m.schema.Pos(m.currCol)+1, len(cols)-1)]
+	}
+	return m, nil
+}
+
+// viewOverview draws the body rows of the cursor's page with the cursor
+// highlighted.
+func (m model) viewOverview() string {
+	cols := m.schema.Columns
+	w := 10
+	if m.width > 0 {
+		w = max(m.width/len(cols)-1, 3)
+	}
+	cell := func(s string) string { return runewidth.FillRight(runewidth.Truncate(s, w, "…"), w) }
+	cur := lipgloss.NewStyle().Reverse(true)
+	changed := lipgloss.NewStyle().Foreground(lipgloss.Color("14")).Underline(true)
+	uncertain := lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Italic(true)
+
+	var b bytes.Buffer
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render("Sheet overview (arrows to look around, "+m.keyName("overview")+" or Esc to go back)") + "\n\n")
+	heads := make([]string, len(cols))
+	for i, c := range cols {
+		heads[i] = cell(m.schema.Labels[c])
+	}
+	b.WriteString(lipgloss.NewStyle().Faint(true).Render(strings.Join(heads, " ")) + "\n")
+	first := m.pageStart()
+	for r := first; r < first+parser.RowCount; r++ {
+		row := m.rows[r]
+		cells := make([]string, len(cols))
+		for i, c := range cols {
+			cells[i] = cell(row.Col[c])
+			switch {
+			case r == m.currRow && c == m.currCol:
+				cells[i] = cur.Render(cells[i])
+			case row.Col[c] != m.savedRow(r).Col[c]:
+				cells[i] = changed.Render(cells[i])
+			case row.Uncertain[c]:
+				cells[i] = uncertain.Render(cells[i])
+			}
+		}
+		b.WriteString(strings.Join(cells, " ") + "\n")
+	}
+	b.WriteString(lipgloss.NewStyle().Italic(true).Render(
+		fmt.Sprintf("\nRow %d, %s • page %d of %d", m.currRow+1, m.schema.Labels[m.currCol], first/parser.RowCount+1, len(m.rows)/parser.RowCount)))
+	return b.String()
+}
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	fp "github.com/charmbracelet/bubbles/filepicker"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"testme/parser"
+)
+
+// pickerList mirrors the file picker's listing and cursor, which the picker
+// keeps to itself, so the entry under the cursor can be summarised. It reads
+// each directory as the picker does and moves with the same key bindings.
+type pickerList struct {
+	dir      string
+	files    []os.DirEntry
+	selected int
+	stack    []int // cursor in each directory above, as the picker keeps it
+}
+
+// read lists the picker's current directory: folders first, then by name,
+// leaving out hidden entries unless the picker shows them.
+func (l *pickerList) read(p fp.Model) {
+	l.dir, l.files = p.CurrentDirectory, nil
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return
+	}
+	slices.SortFunc(entries, func(a, b os.DirEntry) int {
+		if a.IsDir() != b.IsDir() {
+			if a.IsDir() {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Name(), b.Name())
+	})
+	for _, e := range entries {
+		if hidden, _ := fp.IsHidden(e.Name()); hidden && !p.ShowHidden {
+			continue
+		}
+		l.files = append(l.files, e)
+	}
+}
+
+// follow moves the cursor as p, already updated with msg, moved its own.
+func (l *pickerList) follow(p fp.Model, msg tea.Msg) {
+	km, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return
+	}
+	last := len(l.files) - 1
+	switch k := p.KeyMap; {
+	case key.Matches(km, k.GoToTop):
+		l.selected = 0
+	case key.Matches(km, k.GoToLast):
+		l.selected = last
+	case key.Matches(km, k.Down):
+		l.selected = min(l.selected+1, last)
+	case key.Matches(km, k.Up):
+		l.selected = max(l.selected-1, 0)
+	case key.Matches(km, k.PageDown):
+		l.selected = min(l.selected+p.Height, last)
+	case key.Matches(km, k.PageUp):
+		l.selected = max(l.selected-p.Height, 0)
+	case key.Matches(km, k.Back):
+		l.selected = 0
+		if n := len(l.stack); n > 0 {
+			l.selected, l.stack = l.stack[n-1], l.stack[:n-1]
+		}
+		l.read(p)
+	case key.Matches(km, k.Open):
+		if p.CurrentDirectory != l.dir {
+			l.stack = append(l.stack, l.selected)
+			l.selected = 0
+			l.read(p)
+		}
+	}
+}
+
+// highlighted returns the path of the entry under the cursor, or "".
+func (l *pickerList) highlighted() string {
+	if l.selected < 0 || l.selected >= len(l.files) {
+		return ""
+	}
+	return filepath.Join(l.dir, l.files[l.selected].Name())
+}
+
+// peekHighlighted follows the picker's cursor with msg and refreshes the
+// summary of the highlighted file when it has moved to a different one.
+func (m *model) peekHighlighted(msg tea.Msg) {
+	m.pickList.follow(m.picker, msg)
+	path := m.pickList.highlighted()
+	if path == m.peekPath {
+		return
+	}
+	m.peekPath, m.peekInfo = path, ""
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+	default:
+		return
+	}
+	s, err := parser.Peek(path)
+	if err != nil {
+		m.peekInfo = "unrecognized format"
+		return
+	}
+	year, parish := s.Year, s.Parish
+	if year == "" {
+		year = "year unknown"
+	}
+	if parish == "" {
+		parish = "parish not given"
+	}
+	m.peekInfo = fmt.Sprintf("%s • %s • %d row(s)", parish, year, s.Rows)
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPickerPeeksHighlightedFile(t *testing.T) {
+	h := blankSheet(t)
+	h.Type("Great Canfield")
+	h.Key(tea.KeyCtrlW)
+	page, err := os.ReadFile("census.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, src := range map[string][]byte{
+		".hidden.html":   page,
+		"notes.txt":      []byte("not a census"),
+		"a/inner.html":   page,
+		"a/.hidden.html": page,
+	} {
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h.m.picker.SetHeight(10)
+	h.Key(tea.KeyCtrlO)
+	for _, step := range []struct {
+		key  tea.KeyMsg
+		path string
+		info bool
+	}{
+		{tea.KeyMsg{Type: tea.KeyDown}, "census.html", true},
+		{tea.KeyMsg{Type: tea.KeyDown}, "notes.txt", false},
+		{tea.KeyMsg{Type: tea.KeyDown}, "notes.txt", false},
+		{tea.KeyMsg{Type: tea.KeyUp}, "census.html", true},
+		{runes("g"), "a", false},
+		{tea.KeyMsg{Type: tea.KeyRight}, filepath.Join("a", "inner.html"), true},
+		{tea.KeyMsg{Type: tea.KeyDown}, filepath.Join("a", "inner.html"), true},
+		{tea.KeyMsg{Type: tea.KeyLeft}, "a", false},
+		{runes("G"), "notes.txt", false},
+		{tea.KeyMsg{Type: tea.KeyPgUp}, "a", false},
+	} {
+		h.Send(step.key)
+		if h.m.peekPath != step.path {
+			t.Fatalf("after %s: highlighted %q, want %q", step.key, h.m.peekPath, step.path)
+		}
+		if got := strings.Contains(h.m.peekInfo, "Great Canfield"); got != step.info {
+			t.Errorf("after %s on %s: summary %q", step.key, step.path, h.m.peekInfo)
+		}
+		if line := cursorLine(h); !strings.HasSuffix(line, " "+filepath.Base(step.path)) {
+			t.Errorf("after %s: picker's cursor is on %q, want %s", step.key, line, step.path)
+		}
+	}
+}
+
+// runes is the key message for typing s.
+func runes(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+// cursorLine returns the line of the picker's listing under its cursor.
+func cursorLine(h *Harness) string {
+	for _, line := range strings.Split(h.m.picker.View(), "\n") {
+		if strings.Contains(line, h.m.picker.Cursor) {
+			return strings.TrimRight(line, " ")
+		}
+	}
+	return ""
+}
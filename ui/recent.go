@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// remember records path as the most recently used file.
+func (m *model) remember(path string) {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	m.cfg.AddRecent(path)
+}
+
+// openRecent follows the year menu: it offers the recent files that still
+// exist, or goes straight to a blank page when there are none.
+func (m *model) openRecent() {
+	var kept []string
+	for _, p := range m.cfg.Recent {
+		if _, err := os.Stat(p); err == nil {
+			kept = append(kept, p)
+		}
+	}
+	m.cfg.Recent = kept
+	m.mode, m.recentIdx = modeHeader, 0
+	if len(kept) > 0 {
+		m.mode = modeRecent
+	}
+	m.loadCurrent()
+}
+
+// updateRecent moves through the menu, whose first line is a blank page and
+// the rest the recent files.
+func (m model) updateRecent(km tea.KeyMsg) (tea.Model, tea.Cmd) {
+	n := len(m.cfg.Recent) + 1
+	switch km.Type {
+	case tea.KeyCtrlC:
+		return m.quit()
+	case tea.KeyUp:
+		m.recentIdx = (m.recentIdx - 1 + n) % n
+	case tea.KeyDown:
+		m.recentIdx = (m.recentIdx + 1) % n
+	case tea.KeyEsc:
+		m.mode = modeHeader
+		m.loadCurrent()
+	case tea.KeyEnter:
+		m.mode = modeHeader
+		if m.recentIdx > 0 {
+			path := m.cfg.Recent[m.recentIdx-1]
+			if err := m.loadFromHTML(path); err != nil {
+				m.notice = loadError(path, err)
+			} else {
+				m.justRead = true
+				m.remember(path)
+			}
+		}
+		m.loadCurrent()
+		return m, m.notesTick()
+	}
+	return m, nil
+}
+
+func (m model) viewRecent() string {
+	var b bytes.Buffer
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render("Start from:") + "\n\n")
+	for i, item := range append([]string{"a blank page"}, m.cfg.Recent...) {
+		cursor := " "
+		if i == m.recentIdx {
+			cursor = ">"
+		}
+		b.WriteString(fmt.Sprintf("%s %s\n", cursor, item))
+	}
+	b.WriteString("\n(↑/↓ to choose, Enter to open, Esc for a blank page)")
+	return b.String()
+}
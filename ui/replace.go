@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// replaceOpts controls how find-and-replace matches a cell.
+type replaceOpts struct {
+	caseSensitive bool
+	wholeCell     bool
+}
+
+// cellEdit is one body cell find-and-replace would change.
+type cellEdit struct {
+	row, col int
+	from, to string
+}
+
+// replaceCells returns the edits replacing find with repl across the body
+// rows. An empty find matches nothing.
+func replaceCells(rows []Row, find, repl string, o replaceOpts) []cellEdit {
+	if find == "" {
+		return nil
+	}
+	pat := regexp.QuoteMeta(find)
+	if o.wholeCell {
+		pat = "^" + pat + "$"
+	}
+	if !o.caseSensitive {
+		pat = "(?i)" + pat
+	}
+	re := regexp.MustCompile(pat)
+
+	var edits []cellEdit
+	for ri, r := range rows {
+		for ci, v := range r.Col {
+			if !re.MatchString(v) {
+				continue
+			}
+			to := re.ReplaceAllLiteralString(v, repl)
+			if to != v {
+				edits = append(edits, cellEdit{ri, ci, v, to})
+			}
+		}
+	}
+	return edits
+}
+
+func (m *model) openReplace() {
+	m.commitCurrent()
+	m.prevMode, m.mode = m.mode, modeReplace
+	if m.findIn.Placeholder == "" {
+		m.findIn, m.replIn = newInput("Find"), newInput("Replace with")
+	}
+	m.replFocus = 0
+	m.findIn.Focus()
+	m.replIn.Blur()
+}
+
+func (m model) updateReplace(msg tea.Msg) (tea.Model, tea.Cmd) {
+	km, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch km.String() {
+	case "ctrl+c":
+		return m.quit()
+	case "esc":
+		m.mode = m.prevMode
+		m.loadCurrent()
+		return m, nil
+	case "tab", "shift+tab":
+		m.replFocus = 1 - m.replFocus
+		if m.replFocus == 0 {
+			m.findIn.Focus()
+			m.replIn.Blur()
+		} else {
+			m.replIn.Focus()
+			m.findIn.Blur()
+		}
+		return m, nil
+	case "alt+c":
+		m.replOpts.caseSensitive = !m.replOpts.caseSensitive
+		return m, nil
+	case "alt+w":
+		m.replOpts.wholeCell = !m.replOpts.wholeCell
+		return m, nil
+	case "enter":
+		edits := replaceCells(m.rows[:], m.findIn.Value(), m.replIn.Value(), m.replOpts)
+		if len(edits) > 0 {
+			m.checkpoint()
+			for _, e := range edits {
+				m.rows[e.row].Col[e.col] = e.to
+			}
+		}
+		m.mode = m.prevMode
+		m.loadCurrent()
+		m.notice = fmt.Sprintf("replaced %d cell(s)", len(edits))
+		return m, nil
+	}
+	if m.replFocus == 0 {
+		m.findIn, _ = m.findIn.Update(km)
+	} else {
+		m.replIn, _ = m.replIn.Update(km)
+	}
+	return m, nil
+}
+
+// maxPreview caps the cells listed in the find-and-replace preview.
+const maxPreview = 10
+
+func (m model) viewReplace() string {
+	var b bytes.Buffer
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render("Find and replace in body cells:\n\n"))
+	lbl := lipgloss.NewStyle().Padding(0, 1)
+	b.WriteString(lbl.Render(m.findIn.Placeholder) + m.findIn.View() + "\n")
+	b.WriteString(lbl.Render(m.replIn.Placeholder) + m.replIn.View() + "\n\n")
+
+	check := func(on bool) string {
+		if on {
+			return "[x]"
+		}
+		return "[ ]"
+	}
+	b.WriteString(fmt.Sprintf("%s case-sensitive (Alt‑C)   %s whole cell (Alt‑W)\n\n",
+		check(m.replOpts.caseSensitive), check(m.replOpts.wholeCell)))
+
+	edits := replaceCells(m.rows[:], m.findIn.Value(), m.replIn.Value(), m.replOpts)
+	b.WriteString(fmt.Sprintf("%d cell(s) will change\n", len(edits)))
+	for i, e := range edits {
+		if i == maxPreview {
+			b.WriteString(fmt.Sprintf("  … and %d more\n", len(edits)-maxPreview))
+			break
+		}
+		b.WriteString(fmt.Sprintf("  R%dC%d %s: %q → %q\n", e.row+1, e.col+1, m.bodyIn[e.col].Placeholder, e.from, e.to))
+	}
+	b.WriteString("\n(Tab to switch fields, Enter to apply, Esc to cancel)")
+	return b.String()
+}
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+
+	"testme/schema"
+)
+
+func TestReplaceCells(t *testing.T) {
+	rows := make([]Row, 3)
+	rows[0].Col[schema.Address] = "High St"
+	rows[0].Col[schema.Name] = "John Smith"
+	rows[1].Col[schema.Address] = "high st"
+	rows[1].Col[schema.Occupation] = "Ag Lab (St. Mary's)"
+	rows[2].Col[schema.Address] = "High St."
+
+	for _, tc := range []struct {
+		name       string
+		find, repl string
+		o          replaceOpts
+		want       []cellEdit
+	}{
+		{"any case", "st", "Street", replaceOpts{}, []cellEdit{
+			{0, schema.Address, "High St", "High Street"},
+			{1, schema.Address, "high st", "high Street"},
+			{1, schema.Occupation, "Ag Lab (St. Mary's)", "Ag Lab (Street. Mary's)"},
+			{2, schema.Address, "High St.", "High Street."},
+		}},
+		{"case sensitive", "St", "Street", replaceOpts{caseSensitive: true}, []cellEdit{
+			{0, schema.Address, "High St", "High Street"},
+			{1, schema.Occupation, "Ag Lab (St. Mary's)", "Ag Lab (Street. Mary's)"},
+			{2, schema.Address, "High St.", "High Street."},
+		}},
+		{"whole cell", "high st", "High Street", replaceOpts{wholeCell: true}, []cellEdit{
+			{0, schema.Address, "High St", "High Street"},
+			{1, schema.Address, "high st", "High Street"},
+		}},
+		{"whole cell, case sensitive", "High St", "High Street", replaceOpts{true, true}, []cellEdit{
+			{0, schema.Address, "High St", "High Street"},
+		}},
+		{"pattern characters are literal", "St.", "Street", replaceOpts{caseSensitive: true}, []cellEdit{
+			{1, schema.Occupation, "Ag Lab (St. Mary's)", "Ag Lab (Street Mary's)"},
+			{2, schema.Address, "High St.", "High Street"},
+		}},
+		{"no change is no edit", "Smith", "Smith", replaceOpts{}, nil},
+		{"empty find", "", "x", replaceOpts{}, nil},
+	} {
+		if got := replaceCells(rows, tc.find, tc.repl, tc.o); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
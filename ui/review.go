@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"testme/parser"
+)
+
+// issuesAt returns the issues on body cell (row, col).
+func issuesAt(issues []parser.Issue, row, col int) []parser.Issue {
+	var at []parser.Issue
+	for _, is := range issues {
+		if is.Row == row && is.Col == col {
+			at = append(at, is)
+		}
+	}
+	return at
+}
+
+// issueMark renders an issue beside its field: red for errors, yellow for
+// warnings.
+func issueMark(is parser.Issue) string {
+	if is.Error {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render(" ✗ " + is.Msg)
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(" ⚠ " + is.Msg)
+}
+
+// startReview moves to the first flagged cell and steps through the rest
+// from there, or says there is nothing to review.
+func (m *model) startReview() {
+	m.commitCurrent()
+	issues := parser.Validate(m.schema, m.rows[:])
+	if len(issues) == 0 {
+		m.notice = "nothing to review"
+		return
+	}
+	m.reviewing = true
+	m.goToIssue(issues[0])
+}
+
+// nextIssue commits the current cell and moves to the next flagged cell
+// after it, ending the review after the last one.
+func (m *model) nextIssue() {
+	m.commitCurrent()
+	issues := parser.Validate(m.schema, m.rows[:])
+	here := m.currRow*len(m.schema.Columns) + m.schema.Pos(m.currCol)
+	for _, is := range issues {
+		if is.Row*len(m.schema.Columns)+m.schema.Pos(is.Col) > here {
+			m.goToIssue(is)
+			return
+		}
+	}
+	m.reviewing = false
+	m.notice = fmt.Sprintf("review finished: %d issue(s) left", len(issues))
+}
+
+func (m *model) goToIssue(is parser.Issue) {
+	m.mode, m.currRow, m.currCol = modeBody, is.Row, is.Col
+	m.loadCurrent()
+}
+
+// reviewLine describes the cell under review and the keys that go on.
+func (m *model) reviewLine() string {
+	rows := m.liveRows()
+	issues := parser.Validate(m.schema, rows[:])
+	what := "✓ fixed"
+	if at := issuesAt(issues, m.currRow, m.currCol); len(at) > 0 {
+		what = at[0].Describe(m.schema)
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(fmt.Sprintf(
+		"Review (%d issue(s)) — %s. Fix it or not, then Enter or %s for the next; Esc stops",
+		len(issues), what, m.keyName("review")))
+}
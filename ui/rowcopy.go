@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"fmt"
+
+	"testme/parser"
+	"testme/schema"
+)
+
+// addressFields are the cells the address-only paste carries: the house and
+// where born, with 1841's born-abroad column beside it.
+var addressFields = []int{schema.Address, schema.Birthplace, schema.BornAbroad}
+
+// copyRow keeps the current row, as typed so far, for pasting over others.
+func (m *model) copyRow() {
+	m.commitCurrent()
+	if m.rows[m.currRow].Col == ([parser.FieldCount]string{}) {
+		m.notice = "this row is blank; there is nothing to copy"
+		return
+	}
+	m.copied, m.hasCopied = m.rows[m.currRow], true
+	m.notice = fmt.Sprintf("row %d copied", m.currRow+1)
+}
+
+// pasteRow writes the copied row's cells over the current row, every field
+// when fields is nil, with their markup and uncertain marks. The ids the cells
+// were read with stay behind, so each cell keeps one of its own. It undoes as
+// one step.
+func (m *model) pasteRow(fields []int) {
+	if !m.hasCopied {
+		m.notice = "no row copied yet (" + m.keyName("copy-row") + " copies one)"
+		return
+	}
+	m.commitCurrent()
+	m.checkpoint()
+	r := &m.rows[m.currRow]
+	if fields == nil {
+		*r = m.copied
+		r.Ref = [parser.FieldCount]string{}
+	}
+	for _, f := range fields {
+		r.Col[f], r.Kind[f], r.Uncertain[f], r.Ref[f] = m.copied.Col[f], m.copied.Kind[f], m.copied.Uncertain[f], ""
+	}
+	m.loadCurrent()
+}
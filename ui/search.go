@@ -0,0 +1,145 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// searchHit is a cell holding the search text: a header or page field, a
+// body cell or a footer total.
+type searchHit struct {
+	mode     editMode
+	row, col int
+}
+
+// searchHits returns every cell of the sheet containing q, ignoring case, in
+// the order the editor visits them: header, body row by row, then footer.
+func (m *model) searchHits(q string) []searchHit {
+	q = strings.ToLower(q)
+	has := func(v string) bool { return q != "" && strings.Contains(strings.ToLower(v), q) }
+	var hits []searchHit
+	for i, v := range m.header {
+		if has(v) {
+			hits = append(hits, searchHit{modeHeader, 0, i})
+		}
+	}
+	for i, v := range m.meta.Fields() {
+		if has(*v) {
+			hits = append(hits, searchHit{modeHeader, 0, len(m.header) + i})
+		}
+	}
+	for ri, r := range m.rows {
+		for _, ci := range m.schema.Columns {
+			if has(r.Col[ci]) {
+				hits = append(hits, searchHit{modeBody, ri, ci})
+			}
+		}
+	}
+	for i, v := range m.footer {
+		if has(v) {
+			hits = append(hits, searchHit{modeFooter, 0, i})
+		}
+	}
+	return hits
+}
+
+// order places h for comparing positions on the sheet.
+func (m *model) order(h searchHit) []int {
+	col := h.col
+	if h.mode == modeBody {
+		col = m.schema.Pos(col)
+	}
+	return []int{int(h.mode), h.row, col}
+}
+
+// here returns the focused cell as a search position.
+func (m *model) here() searchHit {
+	h := searchHit{m.mode, 0, m.currCol}
+	if m.mode == modeBody {
+		h.row = m.currRow
+	}
+	return h
+}
+
+// openSearch asks for the text to find, starting from the last one.
+func (m *model) openSearch() {
+	if m.searchIn.Placeholder == "" {
+		m.searchIn = newInput("text in any cell")
+	}
+	m.searchIn.SetValue(m.query)
+	m.searchIn.CursorEnd()
+	m.searchIn.Focus()
+	m.searching = true
+}
+
+// updateSearch types into the prompt; Enter finds the first match after the
+// focus and Esc closes it where the focus was.
+func (m model) updateSearch(km tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch km.Type {
+	case tea.KeyCtrlC:
+		return m.quit()
+	case tea.KeyEsc:
+		m.searching = false
+	case tea.KeyEnter:
+		m.searching = false
+		if m.query = strings.TrimSpace(m.searchIn.Value()); m.query != "" {
+			m.nextMatch()
+		}
+	default:
+		m.searchIn, _ = m.searchIn.Update(km)
+	}
+	return m, nil
+}
+
+// nextMatch moves to the first cell after the focus holding the search
+// text, going round to the top of the header after the last one, and
+// switching between header, body and footer as needed.
+func (m *model) nextMatch() {
+	m.commitCurrent()
+	hits := m.searchHits(m.query)
+	if len(hits) == 0 {
+		m.hitOf = 0
+		m.notice = fmt.Sprintf("no cell contains %q", m.query)
+		return
+	}
+	at := slices.IndexFunc(hits, func(h searchHit) bool {
+		return slices.Compare(m.order(h), m.order(m.here())) > 0
+	})
+	at = max(at, 0)
+	h := hits[at]
+	if h.mode != m.mode {
+		m.switchMode(h.mode)
+	}
+	if h.mode == modeBody {
+		m.currRow = h.row
+	}
+	m.currCol = h.col
+	m.loadCurrent()
+	m.hit, m.hitAt, m.hitOf = h, at+1, len(hits)
+}
+
+// onHit reports whether field col of mode is the match the focus was moved
+// to and is still on.
+func (m *model) onHit(mode editMode, col int) bool {
+	return m.hitOf > 0 && m.mode == mode && m.here() == m.hit && m.hit.col == col
+}
+
+// searchLine shows the prompt, or which match the focus is on.
+func (m *model) searchLine() string {
+	switch {
+	case m.searching:
+		return lipgloss.NewStyle().Padding(0, 1).Render("Search:") + m.searchIn.View() + "\n" +
+			lipgloss.NewStyle().Faint(true).Render("(Enter to find, Esc to stay)") + "\n\n"
+	case m.hitOf > 0 && m.here() == m.hit:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(fmt.Sprintf("match %d/%d for %q", m.hitAt, m.hitOf, m.query)) +
+			lipgloss.NewStyle().Faint(true).Render(" ("+m.keyName("next-match")+" for the next)") + "\n\n"
+	}
+	return ""
+}
+
+// hitStyle marks the label of the field a search landed on.
+var hitStyle = lipgloss.NewStyle().Padding(0, 1).Reverse(true)
@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	tpl "testme/template"
+)
+
+// setting is one line on the settings screen: either an on/off flag or a
+// value cycled through a fixed list of choices.
+type setting struct {
+	label   string
+	flag    func(m *model) *bool
+	choice  func(m *model) *string
+	choices []string
+}
+
+var settings = []setting{
+	{label: "Auto-increment schedule number", flag: func(m *model) *bool { return &m.cfg.AutoSchedule }},
+	{label: "Fill address down into blank rows", flag: func(m *model) *bool { return &m.cfg.FillDown }},
+	{label: "Normalize whitespace on commit", flag: func(m *model) *bool { return &m.cfg.NormalizeSpace }},
+	{label: "Write id anchors on footer totals", flag: func(m *model) *bool { return &m.cfg.CellIDs }},
+	{label: "Rule off households in saved HTML", flag: func(m *model) *bool { return &m.cfg.Separators }},
+	{label: "Write one cell per line for version control", flag: func(m *model) *bool { return &m.cfg.Canonical }},
+	{label: "Link birthplaces to a places index", flag: func(m *model) *bool { return &m.cfg.PlaceLinks }},
+	{label: "Link families in the households export", flag: func(m *model) *bool { return &m.cfg.Relationships }},
+	{label: "Refuse to save a sheet with errors", flag: func(m *model) *bool { return &m.cfg.Strict }},
+	{label: "Check saved files read back the same", flag: func(m *model) *bool { return &m.cfg.VerifyWrites }},
+	{label: "Keep the footer when cloning a page", flag: func(m *model) *bool { return &m.cfg.CloneFooter }},
+	{label: "Overwrite typed footer totals without asking", flag: func(m *model) *bool { return &m.cfg.OverwriteTotals }},
+	{label: "Keep footer totals in step with the body", flag: func(m *model) *bool { return &m.cfg.AutoTotals }},
+	{label: "Male and female totals count people or sum ages", choice: func(m *model) *string { return &m.cfg.SexTotals }, choices: sexTotals},
+	{label: "Theme of saved HTML", choice: func(m *model) *string { return &m.cfg.Theme }, choices: tpl.Themes()},
+	{label: "Thousands separator in computed totals", choice: func(m *model) *string { return &m.cfg.Thousands }, choices: thousandsSeps},
+	{label: "Enter key", choice: func(m *model) *string { return &m.cfg.EnterAction }, choices: enterActions},
+}
+
+func (m *model) openSettings() {
+	m.commitCurrent()
+	m.prevMode, m.mode = m.mode, modeSettings
+}
+
+func (m model) updateSettings(km tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if km.Type == tea.KeyEsc || m.keys[km.String()] == "settings" {
+		m.mode = m.prevMode
+		m.loadCurrent()
+		return m, nil
+	}
+	switch km.Type {
+	case tea.KeyCtrlC:
+		return m.quit()
+	case tea.KeyUp:
+		m.settingIdx = (m.settingIdx - 1 + len(settings)) % len(settings)
+	case tea.KeyDown:
+		m.settingIdx = (m.settingIdx + 1) % len(settings)
+	case tea.KeyEnter, tea.KeySpace:
+		s := settings[m.settingIdx]
+		if s.flag != nil {
+			v := s.flag(&m)
+			*v = !*v
+			break
+		}
+		v := s.choice(&m)
+		next := 0
+		for i, c := range s.choices {
+			if c == *v {
+				next = (i + 1) % len(s.choices)
+			}
+		}
+		*v = s.choices[next]
+	}
+	return m, nil
+}
+
+func (m model) viewSettings() string {
+	var b bytes.Buffer
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render("Settings:\n\n"))
+	for i, s := range settings {
+		cursor := " "
+		if i == m.settingIdx {
+			cursor = ">"
+		}
+		var state string
+		switch {
+		case s.flag != nil && *s.flag(&m):
+			state = "[x]"
+		case s.flag != nil:
+			state = "[ ]"
+		default:
+			v := *s.choice(&m)
+			if v == "" {
+				v = s.choices[0]
+			}
+			state = "<" + v + ">"
+		}
+		b.WriteString(fmt.Sprintf("%s %s %s\n", cursor, state, s.label))
+	}
+	b.WriteString("\n(↑/↓ to choose, Space/Enter to change, Esc to go back; saved on quit)\n\n")
+
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render("Keys") + " (change them in keymap.json beside the config file):\n")
+	var keys []string
+	for _, a := range m.keymap.Actions() {
+		keys = append(keys, fmt.Sprintf("%s %s", m.keyName(a), a))
+	}
+	b.WriteString(lipgloss.NewStyle().Faint(true).Width(max(m.width, 80)).Render(strings.Join(keys, " • ")))
+	return b.String()
+}
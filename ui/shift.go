@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"testme/parser"
+)
+
+// insertRow puts a blank row at the current one, moving it and the rows
+// below down one. When the last row of the sheet is filled a page is added
+// for it rather than losing it, unless the sheet already holds
+// parser.MaxRows rows: then the last row is dropped. It undoes as one step.
+func (m *model) insertRow() {
+	m.commitCurrent()
+	m.checkpoint()
+	m.rows = slices.Insert(m.rows, m.currRow, Row{})
+	last := m.rows[len(m.rows)-1]
+	dropped := last != (Row{}) && len(m.rows) > parser.MaxRows
+	if last == (Row{}) || dropped {
+		m.rows = m.rows[:len(m.rows)-1]
+	}
+	m.rows = parser.PadRows(m.rows)
+	m.loadCurrent()
+	m.notice = fmt.Sprintf("blank row inserted at row %d", m.currRow+1)
+	if dropped {
+		m.notice += lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(
+			fmt.Sprintf(" — the sheet holds at most %d rows, so the last one was dropped", parser.MaxRows))
+	}
+}
+
+// deleteRow removes the current row, moving the rows below up one and
+// leaving a blank row at the end of the sheet. It undoes as one step.
+func (m *model) deleteRow() {
+	m.commitCurrent()
+	m.checkpoint()
+	m.rows = append(slices.Delete(m.rows, m.currRow, m.currRow+1), Row{})
+	m.loadCurrent()
+	m.notice = fmt.Sprintf("row %d deleted", m.currRow+1)
+}
@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"testme/parser"
+	"testme/schema"
+)
+
+// fullSheet starts a blank page with n body rows, each holding its own row
+// number as the Sched#.
+func fullSheet(t *testing.T, n int) *Harness {
+	t.Helper()
+	h := blankSheet(t)
+	h.m.rows = make([]Row, n)
+	for i := range h.m.rows {
+		h.m.rows[i].Col[schema.Sched] = strconv.Itoa(i + 1)
+	}
+	h.Key(tea.KeyCtrlB)
+	return h
+}
+
+func TestInsertRowOnFullPageAddsPage(t *testing.T) {
+	h := fullSheet(t, parser.RowCount)
+	h.Key(tea.KeyInsert)
+	if len(h.m.rows) != 2*parser.RowCount {
+		t.Fatalf("%d rows after inserting, want a second page", len(h.m.rows))
+	}
+	if v := h.m.rows[parser.RowCount].Col[schema.Sched]; v != strconv.Itoa(parser.RowCount) {
+		t.Errorf("the last row moved down reads %q", v)
+	}
+}
+
+func TestInsertRowAtMaxRowsDropsLast(t *testing.T) {
+	h := fullSheet(t, parser.MaxRows)
+	h.Key(tea.KeyInsert)
+	if len(h.m.rows) != parser.MaxRows {
+		t.Fatalf("%d rows after inserting, want %d", len(h.m.rows), parser.MaxRows)
+	}
+	if h.m.rows[0] != (Row{}) || h.m.rows[1].Col[schema.Sched] != "1" {
+		t.Errorf("rows 1 and 2 read %q, %q", h.m.rows[0].Col[schema.Sched], h.m.rows[1].Col[schema.Sched])
+	}
+	if v := h.m.rows[parser.MaxRows-1].Col[schema.Sched]; v != strconv.Itoa(parser.MaxRows-1) {
+		t.Errorf("last row reads %q, want %d", v, parser.MaxRows-1)
+	}
+	if !strings.Contains(h.m.notice, "the last one was dropped") {
+		t.Errorf("notice %q does not say the last row was dropped", h.m.notice)
+	}
+	h.Key(tea.KeyCtrlZ)
+	if v := h.m.rows[parser.MaxRows-1].Col[schema.Sched]; v != strconv.Itoa(parser.MaxRows) {
+		t.Errorf("undo left the last row as %q", v)
+	}
+}
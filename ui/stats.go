@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"testme/parser"
+	"testme/schema"
+)
+
+// countPeople returns the number of rows with a name filled in.
+func countPeople(rows []Row) int {
+	n := 0
+	for _, r := range rows {
+		if strings.TrimSpace(r.Col[schema.Name]) != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// countHouseholds returns the number of distinct non-empty schedule numbers,
+// or on a form without them, such as 1841's, the households that
+// parser.HouseholdStarts finds from the changes of address.
+func countHouseholds(sc schema.Schema, rows []Row) int {
+	if !sc.Has(schema.Sched) {
+		n := 0
+		for _, start := range parser.HouseholdStarts(rows) {
+			if start {
+				n++
+			}
+		}
+		return n
+	}
+	seen := map[string]bool{}
+	for _, r := range rows {
+		if s := strings.TrimSpace(r.Col[schema.Sched]); s != "" {
+			seen[s] = true
+		}
+	}
+	return len(seen)
+}
+
+// Stats summarises the numeric columns of a page.
+type Stats struct {
+	Inhabited, Uninhabited int // sums of the house columns
+	Males, Females         int // rows with an age in each sex's column
+	Ages                   int // ages that parsed, across both columns
+	Approx                 int // of those, ranges or uncertain ages
+	MinAge, MaxAge, Mean   float64
+}
+
+// columnStats totals the house columns and summarises the ages across rows.
+// Cells that are not numeric are skipped.
+func columnStats(rows []Row) Stats {
+	var st Stats
+	sum := 0.0
+	for _, r := range rows {
+		if n, err := strconv.Atoi(strings.TrimSpace(r.Col[schema.Inhabited])); err == nil {
+			st.Inhabited += n
+		}
+		if n, err := strconv.Atoi(strings.TrimSpace(r.Col[schema.Uninhabited])); err == nil {
+			st.Uninhabited += n
+		}
+		for _, col := range []int{schema.AgeMale, schema.AgeFemale} {
+			if strings.TrimSpace(r.Col[col]) == "" {
+				continue
+			}
+			if col == schema.AgeMale {
+				st.Males++
+			} else {
+				st.Females++
+			}
+			a, ok := parser.ParseAge(r.Col[col])
+			if !ok {
+				continue
+			}
+			age := a.Years
+			if a.Approx {
+				st.Approx++
+			}
+			if st.Ages == 0 || age < st.MinAge {
+				st.MinAge = age
+			}
+			if st.Ages == 0 || age > st.MaxAge {
+				st.MaxAge = age
+			}
+			sum += age
+			st.Ages++
+		}
+	}
+	if st.Ages > 0 {
+		st.Mean = sum / float64(st.Ages)
+	}
+	return st
+}
+
+func (st Stats) String() string {
+	s := fmt.Sprintf("Houses: %d inhabited, %d uninhabited • People: %d male, %d female",
+		st.Inhabited, st.Uninhabited, st.Males, st.Females)
+	if st.Ages > 0 {
+		s += fmt.Sprintf(" • Age: min %s, max %s, mean %.1f", fmtAge(st.MinAge), fmtAge(st.MaxAge), st.Mean)
+		if st.Approx > 0 {
+			s += fmt.Sprintf(" (%d approximate)", st.Approx)
+		}
+	}
+	return s
+}
+
+// fmtAge prints whole years plainly and infant ages to one decimal place.
+func fmtAge(a float64) string {
+	if a == float64(int(a)) {
+		return strconv.Itoa(int(a))
+	}
+	return strconv.FormatFloat(a, 'f', 1, 64)
+}
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"testing"
+
+	"testme/schema"
+)
+
+// statRow builds a body row from field-value pairs.
+func statRow(kv ...any) Row {
+	var r Row
+	for i := 0; i < len(kv); i += 2 {
+		r.Col[kv[i].(int)] = kv[i+1].(string)
+	}
+	return r
+}
+
+func TestCountPeopleAndHouseholds(t *testing.T) {
+	rows := []Row{
+		statRow(schema.Sched, "1", schema.Name, "John Smith"),
+		statRow(schema.Sched, "1", schema.Name, "Mary Smith"),
+		statRow(schema.Sched, " 2 ", schema.Name, "  "),
+		statRow(schema.Sched, "2", schema.Name, "Ann Brown"),
+		statRow(schema.Name, "Lodger"),
+		{},
+	}
+	if n := countPeople(rows); n != 4 {
+		t.Errorf("countPeople = %d, want 4", n)
+	}
+	if n := countHouseholds(schema.For("1861"), rows); n != 2 {
+		t.Errorf("countHouseholds = %d, want 2", n)
+	}
+	if n := countPeople(nil); n != 0 {
+		t.Errorf("countPeople(nil) = %d", n)
+	}
+	if n := countHouseholds(schema.For("1861"), nil); n != 0 {
+		t.Errorf("countHouseholds(nil) = %d", n)
+	}
+}
+
+func TestCountHouseholds1841(t *testing.T) {
+	rows := []Row{
+		statRow(schema.Address, "High Street", schema.Name, "John Smith"),
+		statRow(schema.Name, "Mary Smith"),
+		statRow(schema.Address, "High Street", schema.Name, "Ann Smith"),
+		{},
+		statRow(schema.Address, "Mill Lane", schema.Name, "Ann Brown"),
+		statRow(schema.Address, "Church Row", schema.Name, "Tom Green"),
+	}
+	if n := countHouseholds(schema.For("1841"), rows); n != 3 {
+		t.Errorf("countHouseholds = %d, want 3", n)
+	}
+}
+
+func TestColumnStats(t *testing.T) {
+	rows := []Row{
+		statRow(schema.Inhabited, "1", schema.AgeMale, "40"),
+		statRow(schema.AgeFemale, "c. 38"),
+		statRow(schema.AgeFemale, "6 mo"),
+		statRow(schema.Inhabited, "x", schema.Uninhabited, "2", schema.AgeMale, "unknown"),
+		statRow(schema.Inhabited, " 1 ", schema.AgeMale, "10-12"),
+		{},
+	}
+	st := columnStats(rows)
+	want := Stats{
+		Inhabited: 2, Uninhabited: 2,
+		Males: 3, Females: 2,
+		Ages: 4, Approx: 2,
+		MinAge: 0.5, MaxAge: 40,
+		Mean: (40 + 38 + 0.5 + 11) / 4,
+	}
+	if st != want {
+		t.Errorf("columnStats =\n%+v, want\n%+v", st, want)
+	}
+}
+
+func TestColumnStatsNoAges(t *testing.T) {
+	st := columnStats([]Row{statRow(schema.Inhabited, "1")})
+	if st != (Stats{Inhabited: 1}) {
+		t.Errorf("columnStats = %+v", st)
+	}
+	if got, want := st.String(), "Houses: 1 inhabited, 0 uninhabited • People: 0 male, 0 female"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
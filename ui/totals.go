@@ -0,0 +1,156 @@
+package ui
+
+import (
+	"strings"
+
+	"testme/parser"
+	"testme/schema"
+)
+
+// footNames labels the footer totals in messages.
+var footNames = [parser.FootCount]string{"houses inhabited", "houses uninhabited", "males", "females"}
+
+// thousandsSeps lists the choices for grouping the digits of computed
+// totals, default first; sepOf gives the separator each one writes.
+var (
+	thousandsSeps = []string{"none", "comma", "space"}
+	sepOf         = map[string]string{"comma": ",", "space": " "}
+)
+
+// sexTotals lists what the male and female totals can add up, default
+// first.
+var sexTotals = []string{"count", "ages"}
+
+// totalsOf returns the footer totals implied by the body rows, grouped by
+// sep. The male and female totals count the rows with an age in each
+// column, or with sumAges add up the whole years of those ages.
+func totalsOf(rows []Row, sep string, sumAges bool) [parser.FootCount]string {
+	st := columnStats(rows)
+	males, females := st.Males, st.Females
+	if sumAges {
+		males, females = sumAge(rows, schema.AgeMale), sumAge(rows, schema.AgeFemale)
+	}
+	return [parser.FootCount]string{
+		parser.FormatCount(st.Inhabited, sep), parser.FormatCount(st.Uninhabited, sep),
+		parser.FormatCount(males, sep), parser.FormatCount(females, sep),
+	}
+}
+
+// sumAge adds up the whole years of the ages in column col, skipping cells
+// that are not ages.
+func sumAge(rows []Row, col int) int {
+	n := 0
+	for _, r := range rows {
+		if a, ok := parser.ParseAge(r.Col[col]); ok {
+			n += int(a.Years)
+		}
+	}
+	return n
+}
+
+// totals returns the footer totals implied by the committed body, as the
+// settings ask for them.
+func (m *model) totals() [parser.FootCount]string {
+	return totalsOf(m.rows[:], sepOf[m.cfg.Thousands], m.cfg.SexTotals == "ages")
+}
+
+// sameCount reports whether two footer totals are the same number however
+// their digits are grouped, so "1,234" matches a computed 1234. Values that
+// are not counts match only when they are the same text.
+func (m *model) sameCount(a, b string) bool {
+	if a == b {
+		return true
+	}
+	x, ok1 := m.readCount(a)
+	y, ok2 := m.readCount(b)
+	return ok1 && ok2 && x == y
+}
+
+// readCount reads a footer total grouped by the configured separator or by
+// commas.
+func (m *model) readCount(s string) (int, bool) {
+	if n, ok := parser.ParseCount(s, sepOf[m.cfg.Thousands]); ok {
+		return n, true
+	}
+	return parser.ParseCount(s, ",")
+}
+
+// computeTotals fills the footer from the body. Blank footer cells take the
+// computed value at once; cells already holding a different value are only
+// replaced after confirmation, since the enumerator's own arithmetic is part
+// of the record.
+func (m *model) computeTotals() {
+	m.commitCurrent()
+	totals := m.totals()
+	m.applyTotals(totals, m.cfg.OverwriteTotals)
+	for i, v := range m.footer {
+		if !m.sameCount(v, totals[i]) {
+			m.pendingTotals, m.confirmTotals = totals, true
+			return
+		}
+	}
+}
+
+// applyTotals writes totals into the blank footer cells, or into every cell
+// holding a different number when overwrite is set, as one undo step.
+func (m *model) applyTotals(totals [parser.FootCount]string, overwrite bool) {
+	next := m.footer
+	for i, v := range totals {
+		if strings.TrimSpace(next[i]) == "" || overwrite && !m.sameCount(next[i], v) {
+			next[i], m.footManual[i] = v, false
+		}
+	}
+	if next != m.footer {
+		m.checkpoint()
+		m.footer = next
+		m.loadCurrent()
+	}
+}
+
+// recomputeTotals refreshes the footer totals from the body when the
+// setting keeps them in step. Cells typed by hand are left alone until they
+// are cleared.
+func (m *model) recomputeTotals() {
+	if !m.cfg.AutoTotals {
+		return
+	}
+	m.commitCurrent()
+	next := m.footer
+	for i, v := range m.totals() {
+		if !m.typed(i) {
+			next[i] = v
+		}
+	}
+	if next != m.footer {
+		m.checkpoint()
+		m.footer = next
+		m.loadCurrent()
+	}
+}
+
+// typed reports whether footer cell i holds a total typed by hand. A blank
+// cell never does, however it was cleared.
+func (m *model) typed(i int) bool {
+	return m.footManual[i] && strings.TrimSpace(m.footer[i]) != ""
+}
+
+// typedTotals marks the footer cells of a loaded page that recomputeTotals
+// should leave alone: those holding a total other than the body's.
+func (m *model) typedTotals() {
+	totals := m.totals()
+	for i, v := range m.footer {
+		m.footManual[i] = strings.TrimSpace(v) != "" && !m.sameCount(v, totals[i])
+	}
+}
+
+// totalsDiff lists the footer cells whose pending total differs, as
+// "males 12 → 14".
+func (m *model) totalsDiff() string {
+	var parts []string
+	for i, v := range m.pendingTotals {
+		if !m.sameCount(m.footer[i], v) {
+			parts = append(parts, footNames[i]+" "+m.footer[i]+" → "+v)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
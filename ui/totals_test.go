@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"testme/parser"
+	"testme/schema"
+	tpl "testme/template"
+)
+
+func TestLoadedTotalsStayTyped(t *testing.T) {
+	t.Chdir(t.TempDir())
+	var row Row
+	row.Col[schema.Sched], row.Col[schema.Inhabited] = "1", "1"
+	row.Col[schema.Name], row.Col[schema.AgeMale] = "John Smith", "40"
+	c := parser.Census{Year: "1861", Rows: parser.PadRows([]Row{row}), Footer: [parser.FootCount]string{"2", "", "3", ""}}
+	f, err := os.Create("page.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tpl.RenderHTML(f, c, tpl.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	h := NewHarness()
+	h.Config().AutoTotals = true
+	h.Config().Recent = []string{filepath.Join(".", "page.html")}
+	h.Key(tea.KeyEnter, tea.KeyDown, tea.KeyEnter)
+	want := [parser.FootCount]string{"2", "0", "3", "0"}
+	if h.m.footManual != [parser.FootCount]bool{true, false, true, false} {
+		t.Errorf("loaded totals typed by hand: %v", h.m.footManual)
+	}
+
+	h.Key(tea.KeyCtrlF)
+	if h.m.footer != want {
+		t.Errorf("footer on entering it %q, want %q", h.m.footer, want)
+	}
+	h.Key(tea.KeyCtrlW)
+	back, err := parser.ParseHTML("census.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.Footer != want {
+		t.Errorf("saved footer %q, want %q", back.Footer, want)
+	}
+
+	h.Key(tea.KeyCtrlZ)
+	if h.m.footer != c.Footer || h.m.footManual != [parser.FootCount]bool{true, false, true, false} {
+		t.Errorf("undo left footer %q, typed %v", h.m.footer, h.m.footManual)
+	}
+	h.Key(tea.KeyCtrlZ)
+	if h.m.footManual != [parser.FootCount]bool{} {
+		t.Errorf("undoing the load left totals typed: %v", h.m.footManual)
+	}
+}
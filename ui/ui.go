@@ -2,15 +2,26 @@ package ui
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	fp "github.com/charmbracelet/bubbles/filepicker"
+	"github.com/charmbracelet/bubbles/key"
 	ti "github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"testme/config"
+	"testme/export"
 	"testme/parser"
+	"testme/schema"
 	tpl "testme/template"
 )
 
@@ -18,14 +29,19 @@ import (
 
 type editMode int
 
-var censusYears = []string{"1841", "1851", "1861", "1871", "1881", "1891", "1901", "1911", "1921"}
-
 const (
 	modeYearSelect editMode = iota
 	modeHeader
 	modeBody
 	modeFooter
 	modePickFile
+	modeSettings
+	modeReplace
+	modeOverview
+	modeCalc
+	modeRecent
+	modeMerge
+	modeList
 )
 
 var modeNames = []string{"YEAR", "HEADER", "BODY", "FOOTER"}
@@ -35,12 +51,28 @@ type Row = parser.Row
 type model struct {
 	// persistent data
 	header [parser.HeadCount]string
-	rows   [parser.RowCount]Row
+	meta   parser.Meta
+	rows   []Row // whole pages of parser.RowCount rows
 	footer [parser.FootCount]string
 
+	// preferences, written back to disk on quit unless ephemeral
+	cfg        config.Config
+	ephemeral  bool
+	settingIdx int
+	prevMode   editMode
+
+	// tick schedules a timed message: tea.Tick, unless the test harness
+	// holds the timers back itself
+	tick func(time.Duration, func(time.Time) tea.Msg) tea.Cmd
+
 	// year selection
+	years   []string // the menu, from the config
 	year    string
 	yearIdx int
+	schema  schema.Schema // body layout of year
+
+	// recentIdx is the highlighted line of the recent files menu
+	recentIdx int
 
 	// editing state
 	mode      editMode
@@ -48,12 +80,138 @@ type model struct {
 	currCol   int
 	justWrote bool
 	justRead  bool
+	readNote  string
+	notice    string // one-shot status line, cleared on the next update
+
+	// readNotes are the parser's notes on the page last opened, shown under
+	// the form until notesUntil
+	readNotes  []string
+	notesUntil time.Time
+
+	// confirmClear is set after Ctrl-N in header/footer mode; a second
+	// Ctrl-N wipes the whole block, any other key cancels.
+	confirmClear bool
+
+	// pendingPaste is a page from the clipboard waiting for the paste key
+	// again before it replaces a sheet that is not blank.
+	pendingPaste parser.Census
+	confirmPaste bool
+
+	// lastCleared holds the row most recently wiped by Ctrl-N so Alt-N can
+	// put it back; canRestore says whether it is still available.
+	lastCleared Row
+	canRestore  bool
+
+	// copied is the row Alt-Y took for pasting over others; hasCopied says
+	// whether there is one.
+	copied    Row
+	hasCopied bool
+
+	// pendingTotals holds computed footer totals that differ from values
+	// already entered; confirmTotals asks whether to overwrite them.
+	pendingTotals [parser.FootCount]string
+	confirmTotals bool
+	// confirmQuit asks before quitting with changes never saved.
+	confirmQuit bool
+	// offerRecovery asks on the year menu whether to open a backup newer
+	// than the saved page; autosavedAt and autosaveErr report the last backup.
+	offerRecovery bool
+	autosavedAt   time.Time
+	autosaveErr   error
+	// footManual marks the footer totals typed by hand, which
+	// recomputeTotals leaves alone until they are cleared.
+	footManual [parser.FootCount]bool
+
+	// saved is the sheet as last written or loaded; confirmClone asks before
+	// Alt-C replaces a sheet with changes since.
+	saved        snapshot
+	confirmClone bool
+
+	// byColumn swaps the body navigation axes so entry runs down a column:
+	// Tab, Shift-Tab and Enter move between rows, ↑/↓ between columns.
+	byColumn bool
+
+	// blocking holds the errors that stopped a write under the strict
+	// setting; confirmForce is the action that pressing again forces.
+	blocking     []parser.Issue
+	confirmForce string
+
+	// markup is the HTML the focused body cell would be written as, shown
+	// until the next key.
+	markup string
+
+	// reviewing steps through the flagged body cells: Enter or the review
+	// key moves on to the next, Esc stops.
+	reviewing bool
+
+	// out is the page Ctrl-W writes; the other formats are written beside
+	// it under the same name.
+	out string
+
+	// lastSaved is when the page was last written, zero until it is
+	lastSaved time.Time
+
+	// template is the page template file the HTML is written with, "" for
+	// the built-in page
+	template string
+
+	// fileYear is the census year named by a just-loaded file when it differs
+	// from m.year; the user is asked whether to adopt it.
+	fileYear string
+
+	// find and replace
+	findIn, replIn ti.Model
+	replFocus      int
+	replOpts       replaceOpts
+
+	// age calculator
+	calcIn ti.Model
+
+	// goingTo shows the go-to-row prompt above the body, typed into gotoIn
+	goingTo bool
+	gotoIn  ti.Model
+
+	// searching shows the search prompt, typed into searchIn; query is the
+	// text last searched for and hit the match the focus was moved to, hitAt
+	// of hitOf
+	searching    bool
+	searchIn     ti.Model
+	query        string
+	hit          searchHit
+	hitAt, hitOf int
+
+	// birthplace spellings being merged, and the variant under the cursor
+	merge    []placeGroup
+	mergeCur int
+
+	// terminal size and the side preview toggle
+	width, height int
+	preview       bool
+
+	// editor position kept while the overview is open
+	editRow, editCol int
+
+	// the row list: its search and the line under the cursor
+	listIn  ti.Model
+	listCur int
+
+	// keys maps each bound key to its action; keymap is the reverse
+	keys   map[string]string
+	keymap config.Keymap
+
+	// undo history of whole-sheet snapshots
+	undoStack, redoStack []snapshot
 
 	// widgets
 	headIn [parser.HeadCount]ti.Model
+	metaIn [parser.MetaCount]ti.Model // edited in header mode after headIn
 	bodyIn [parser.FieldCount]ti.Model
 	footIn [parser.FootCount]ti.Model
 	picker fp.Model
+
+	// summary of the file highlighted in the picker, found from its listing
+	pickList           pickerList
+	peekPath, peekInfo string
 }
 
 func newInput(ph string) ti.Model {
@@ -63,22 +221,24 @@ func newInput(ph string) ti.Model {
 	return in
 }
 
-func NewModel() model {
-	m := model{}
+// headLbl and footLbl are the default input labels of the header and footer.
+var (
+	headLbl = [parser.HeadCount]string{"Parish", "City", "Ward", "Parl Borough", "Town", "Hamlet", "Ecc District"}
+	footLbl = [parser.FootCount]string{"Houses Inhab", "Houses Uninh", "Total Males", "Total Females"}
+)
 
-	headLbl := []string{"Parish", "City", "Ward", "Parl Borough", "Town", "Hamlet", "Ecc District"}
-	bodyLbl := []string{
-		"Sched#", "Road / House", "Inhab.", "Uninh.",
-		"Name & Surname", "Relation", "Condition",
-		"Age♂", "Age♀", "Occupation", "Where born", "Blind/Deaf",
-	}
-	footLbl := []string{"Houses Inhab", "Houses Uninh", "Total Males", "Total Females"}
+func NewModel() model {
+	m := model{rows: parser.PadRows(nil), saved: snapshot{rows: parser.PadRows(nil)}, out: DefaultOut, tick: tea.Tick}
 
 	for i := range m.headIn {
 		m.headIn[i] = newInput(headLbl[i])
 	}
+	for i := range m.metaIn {
+		m.metaIn[i] = newInput(parser.MetaLabels[i])
+	}
 	for i := range m.bodyIn {
-		m.bodyIn[i] = newInput(bodyLbl[i])
+		m.bodyIn[i] = newInput("")
+		m.bodyIn[i].CharLimit = schema.Limits[i]
 	}
 	for i := range m.footIn {
 		m.footIn[i] = newInput(footLbl[i])
@@ -87,54 +247,120 @@ func NewModel() model {
 
 	// file-picker
 	p := fp.New()
-	p.AllowedTypes = []string{".html", ".htm"}
+	p.AllowedTypes = []string{".html", ".htm", ".csv", ".json"}
 	m.picker = p
 
 	m.mode = modeYearSelect
-	m.yearIdx = 2 // default to 1861
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config error: %v\n", err)
+	}
+	m.cfg = cfg
+	if m.years, m.yearIdx, err = cfg.YearMenu(); err != nil {
+		fmt.Fprintf(os.Stderr, "config error: %v\n", err)
+	}
+	km, err := config.LoadKeymap()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "keymap error: %v\n", err)
+	}
+	m.setKeymap(km)
+	m.applySchema()
+	m.offerRecovery = m.newerBackup()
 
 	return m
 }
 
 /* ============== TEA ============== */
 
-func (m model) Init() tea.Cmd { return nil }
+func (m model) Init() tea.Cmd { return tea.Batch(m.autosaveTick(), m.notesTick()) }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	m.justWrote, m.justRead = false, false
+	if t, ok := msg.(autosaveMsg); ok {
+		m.autosave(time.Time(t))
+		return m, m.autosaveTick()
+	}
+	if _, ok := msg.(notesMsg); ok {
+		if !time.Now().Before(m.notesUntil) {
+			m.readNotes = nil
+		}
+		return m, nil
+	}
+	m.justWrote, m.justRead, m.notice = false, false, ""
+
+	if ws, ok := msg.(tea.WindowSizeMsg); ok {
+		m.width, m.height = ws.Width, ws.Height
+		m.sizeInputs()
+		return m, nil
+	}
+
+	if km, ok := msg.(tea.KeyMsg); ok && m.confirmQuit {
+		m.confirmQuit = false
+		switch {
+		case km.String() == "ctrl+q":
+			return m.exit()
+		case m.keys[km.String()] == "write":
+			m.recomputeTotals()
+			if m.blocked("write") {
+				break
+			}
+			m.write()
+			if m.saved.sameData(m.snap()) {
+				return m.exit()
+			}
+		}
+		return m, nil
+	}
 
 	/* ---------- YEAR SELECT MODE ----------- */
 	if m.mode == modeYearSelect {
+		if km, ok := msg.(tea.KeyMsg); ok && m.offerRecovery {
+			m.offerRecovery = false
+			if km.String() == "y" || km.String() == "Y" {
+				m.recoverBackup()
+			}
+			return m, nil
+		}
 		if km, ok := msg.(tea.KeyMsg); ok {
 			switch km.Type {
 			case tea.KeyEsc, tea.KeyCtrlC:
-				return m, tea.Quit
+				return m.quit()
 			case tea.KeyUp:
-				m.yearIdx = (m.yearIdx - 1 + len(censusYears)) % len(censusYears)
+				m.yearIdx = (m.yearIdx - 1 + len(m.years)) % len(m.years)
 			case tea.KeyDown:
-				m.yearIdx = (m.yearIdx + 1) % len(censusYears)
+				m.yearIdx = (m.yearIdx + 1) % len(m.years)
 			case tea.KeyEnter:
-				m.year = censusYears[m.yearIdx]
-				m.mode = modeHeader
-				m.loadCurrent()
+				m.year = m.years[m.yearIdx]
+				m.applySchema()
+				m.openRecent()
 			}
 		}
 		return m, nil
 	}
 
+	/* ---------- RECENT FILES MODE -------- */
+	if m.mode == modeRecent {
+		if km, ok := msg.(tea.KeyMsg); ok {
+			return m.updateRecent(km)
+		}
+		return m, nil
+	}
+
 	/* ---------- FILE‑PICKER MODE ---------- */
 	if m.mode == modePickFile {
 		var cmd tea.Cmd
 		m.picker, cmd = m.picker.Update(msg)
+		m.peekHighlighted(msg)
 
 		if didSel, path := m.picker.DidSelectFile(msg); didSel {
 			if err := m.loadFromHTML(path); err == nil {
 				m.justRead = true
+				m.remember(path)
 			} else {
-				fmt.Fprintf(os.Stderr, "load error: %v\n", err)
+				m.notice = loadError(path, err)
 			}
 			m.mode = modeHeader
-			return m, nil
+			return m, m.notesTick()
 		}
 
 		if km, ok := msg.(tea.KeyMsg); ok && (km.Type == tea.KeyEsc || km.Type == tea.KeyCtrlC) {
@@ -144,61 +370,362 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 	}
 
+	/* ---------- SETTINGS MODE ------------- */
+	if m.mode == modeSettings {
+		if km, ok := msg.(tea.KeyMsg); ok {
+			return m.updateSettings(km)
+		}
+		return m, nil
+	}
+
+	/* ---------- OVERVIEW MODE ------------ */
+	if m.mode == modeOverview {
+		if km, ok := msg.(tea.KeyMsg); ok {
+			return m.updateOverview(km)
+		}
+		return m, nil
+	}
+
+	/* ---------- ROW LIST MODE ------------ */
+	if m.mode == modeList {
+		if km, ok := msg.(tea.KeyMsg); ok {
+			return m.updateList(km)
+		}
+		return m, nil
+	}
+
+	/* ---------- CALCULATOR MODE ---------- */
+	if m.mode == modeCalc {
+		return m.updateCalc(msg)
+	}
+
+	/* ---------- FIND & REPLACE MODE ------ */
+	if m.mode == modeReplace {
+		return m.updateReplace(msg)
+	}
+
+	/* ---------- BIRTHPLACE MERGE MODE ---- */
+	if m.mode == modeMerge {
+		return m.updateMerge(msg)
+	}
+
 	/* ---------- EDITING MODES ------------- */
+	if km, ok := msg.(tea.KeyMsg); ok && m.goingTo {
+		return m.updateGoto(km)
+	}
+
+	if km, ok := msg.(tea.KeyMsg); ok && m.searching {
+		return m.updateSearch(km)
+	}
+
+	if km, ok := msg.(tea.KeyMsg); ok && m.confirmClear {
+		m.confirmClear = false
+		if m.keys[km.String()] == "clear" {
+			m.clearBlock()
+		}
+		return m, nil
+	}
+
+	if km, ok := msg.(tea.KeyMsg); ok && m.confirmPaste {
+		m.confirmPaste = false
+		if m.keys[km.String()] == "paste" {
+			m.loadCensus(m.pendingPaste)
+			m.justRead = true
+		}
+		m.pendingPaste = parser.Census{}
+		return m, nil
+	}
+
+	if km, ok := msg.(tea.KeyMsg); ok && m.confirmTotals {
+		m.confirmTotals = false
+		if km.String() == "y" || km.String() == "Y" {
+			m.applyTotals(m.pendingTotals, true)
+		}
+		return m, nil
+	}
+
+	if km, ok := msg.(tea.KeyMsg); ok && m.confirmClone {
+		m.confirmClone = false
+		if km.String() == "y" || km.String() == "Y" {
+			m.clonePage()
+		}
+		return m, nil
+	}
+
+	if km, ok := msg.(tea.KeyMsg); ok && m.confirmForce != "" {
+		action := m.confirmForce
+		m.confirmForce, m.blocking = "", nil
+		switch {
+		case m.keys[km.String()] != action:
+		case action == "write":
+			m.write()
+		case action == "export-csv":
+			m.exportCSV()
+		case action == "export-txt":
+			m.exportTXT()
+		case action == "save-json":
+			m.saveJSON()
+		default:
+			m.exportAll()
+		}
+		return m, nil
+	}
+
+	if _, ok := msg.(tea.KeyMsg); ok && m.markup != "" {
+		m.markup = ""
+		return m, nil
+	}
+
+	if km, ok := msg.(tea.KeyMsg); ok && m.reviewing && m.mode == modeBody {
+		switch {
+		case km.Type == tea.KeyEsc:
+			m.reviewing = false
+			return m, nil
+		case km.Type == tea.KeyEnter || m.keys[km.String()] == "review":
+			m.nextIssue()
+			return m, nil
+		}
+	}
+
+	if km, ok := msg.(tea.KeyMsg); ok && m.fileYear != "" {
+		if km.String() == "y" || km.String() == "Y" {
+			m.setYear(m.fileYear)
+		}
+		m.fileYear = ""
+		return m, nil
+	}
+
 	switch k := msg.(type) {
 	case tea.KeyMsg:
-		switch k.Type {
-		case tea.KeyEsc, tea.KeyCtrlC:
-			return m, tea.Quit
-		case tea.KeyCtrlH:
+		action := m.keys[k.String()]
+		if k.Type == tea.KeyCtrlC {
+			action = "quit"
+		}
+		switch action {
+		case "quit":
+			return m.quit()
+		case "settings":
+			m.openSettings()
+		case "header":
 			m.switchMode(modeHeader)
-		case tea.KeyCtrlB:
+		case "body":
 			m.switchMode(modeBody)
-		case tea.KeyCtrlF:
+		case "footer":
 			m.switchMode(modeFooter)
-		case tea.KeyCtrlO:
+		case "next-area":
+			m.switchMode(modeHeader + (m.mode-modeHeader+1)%3)
+		case "open":
 			m.commitCurrent()
 			m.mode = modePickFile
+			m.pickList.read(m.picker)
+			m.peekHighlighted(nil)
 			return m, m.picker.Init()
-		case tea.KeyTab:
-			m.currCol++
-			m.wrapCol()
-			m.setFocus()
-		case tea.KeyShiftTab:
-			m.currCol--
-			m.wrapCol()
-			m.setFocus()
-		case tea.KeyUp:
-			if m.mode == modeBody && m.currRow > 0 {
-				m.commitCurrent()
-				m.currRow--
-				m.loadCurrent()
+		case "write", "export-all", "export-csv", "export-txt", "save-json":
+			m.commitCurrent()
+			m.recomputeTotals()
+			switch {
+			case m.blocked(action):
+			case action == "write":
+				m.write()
+			case action == "export-csv":
+				m.exportCSV()
+			case action == "export-txt":
+				m.exportTXT()
+			case action == "save-json":
+				m.saveJSON()
+			default:
+				m.exportAll()
 			}
-		case tea.KeyDown:
-			if m.mode == modeBody && m.currRow < parser.RowCount-1 {
+		case "clear":
+			if m.mode != modeBody {
+				m.confirmClear = true
+				break
+			}
+			m.commitCurrent()
+			if m.rows[m.currRow] != (Row{}) {
+				m.lastCleared, m.canRestore = m.rows[m.currRow], true
+			}
+			m.checkpoint()
+			m.rows[m.currRow] = Row{}
+			m.loadCurrent()
+		case "undo":
+			if !m.undo() {
+				m.notice = "nothing to undo"
+			}
+		case "redo":
+			if !m.redo() {
+				m.notice = "nothing to redo"
+			}
+		case "ref":
+			if m.mode == modeBody {
+				m.cycleKind()
+			}
+		case "paste":
+			m.paste()
+		case "replace":
+			m.openReplace()
+		case "preview":
+			if m.width < minPreviewWidth {
+				m.notice = fmt.Sprintf("preview needs a terminal at least %d columns wide", minPreviewWidth)
+			} else {
+				m.preview = !m.preview
+				m.sizeInputs()
+			}
+		case "restore-row":
+			if m.mode == modeBody && m.canRestore {
 				m.commitCurrent()
-				m.currRow++
+				m.checkpoint()
+				m.rows[m.currRow], m.canRestore = m.lastCleared, false
 				m.loadCurrent()
 			}
-		case tea.KeyCtrlN:
+		case "stats":
+			rows := m.liveRows()
+			m.notice = columnStats(rows[:]).String()
+		case "totals":
+			m.computeTotals()
+		case "clone-page":
+			m.commitCurrent()
+			if m.saved.sameData(m.snap()) {
+				m.clonePage()
+			} else {
+				m.confirmClone = true
+			}
+		case "markup":
 			if m.mode == modeBody {
-				for i := range m.bodyIn {
-					m.bodyIn[i].SetValue("")
-				}
+				m.showMarkup()
 			}
-		case tea.KeyCtrlW:
-			m.commitCurrent()
-			if err := tpl.WriteHTML(m.header, m.rows[:], m.footer, "census.html"); err == nil {
-				m.justWrote = true
+		case "uncertain":
+			if m.mode == modeBody {
+				m.toggleUncertain()
+			}
+		case "swap-name":
+			if m.mode == modeBody {
+				m.swapName()
+			}
+		case "ditto":
+			if m.mode == modeBody {
+				m.ditto()
+			}
+		case "cycle-value":
+			if m.mode == modeBody {
+				m.cycleValue()
+			}
+		case "insert-row":
+			if m.mode == modeBody {
+				m.insertRow()
+			}
+		case "delete-row":
+			if m.mode == modeBody {
+				m.deleteRow()
+			}
+		case "copy-row":
+			if m.mode == modeBody {
+				m.copyRow()
+			}
+		case "paste-row":
+			if m.mode == modeBody {
+				m.pasteRow(nil)
+			}
+		case "paste-address":
+			if m.mode == modeBody {
+				m.pasteRow(addressFields)
+			}
+		case "same-address":
+			if m.mode == modeBody {
+				m.jumpToAddress()
+			}
+		case "overview":
+			m.openOverview()
+		case "list":
+			m.openList()
+		case "goto-row":
+			if m.mode == modeBody {
+				m.openGoto()
+			}
+		case "search":
+			m.openSearch()
+		case "next-match":
+			if m.query == "" {
+				m.openSearch()
 			} else {
-				fmt.Fprintf(os.Stderr, "save error: %v\n", err)
+				m.nextMatch()
+			}
+		case "next-page":
+			m.turnPage(1)
+		case "prev-page":
+			m.turnPage(-1)
+		case "merge-places":
+			m.openMerge()
+		case "calculator":
+			m.openCalc()
+		case "review":
+			m.startReview()
+		case "by-column":
+			if m.mode == modeBody {
+				m.byColumn = !m.byColumn
+			}
+		}
+		if action != "" {
+			return m, nil
+		}
+
+		if m.mode == modeBody {
+			if d, ok := gridKeys[m.cfg.GridKeys][k.String()]; ok {
+				m.moveRow(d[0])
+				m.moveCol(d[1])
+				return m, nil
+			}
+		}
+		if i, ok := choiceKey(k); ok && m.mode == modeBody && i < len(m.schema.Choices[m.currCol]) {
+			m.toggleChoice(i)
+			return m, nil
+		}
+		if m.byColumn && m.mode == modeBody {
+			switch k.Type {
+			case tea.KeyTab:
+				m.stepDown(1)
+				return m, nil
+			case tea.KeyShiftTab:
+				m.stepDown(-1)
+				return m, nil
+			case tea.KeyUp:
+				m.moveCol(-1)
+				return m, nil
+			case tea.KeyDown:
+				m.moveCol(1)
+				return m, nil
+			}
+		}
+
+		switch k.Type {
+		case tea.KeyEnter:
+			if m.enter() {
+				return m, nil
+			}
+		case tea.KeyTab:
+			m.moveCol(1)
+		case tea.KeyShiftTab:
+			m.moveCol(-1)
+		case tea.KeyUp:
+			if m.mode == modeBody {
+				m.moveRow(-1)
+			}
+		case tea.KeyDown:
+			if m.mode == modeBody {
+				m.moveRow(1)
 			}
 		}
 
+		if s := m.proposedSchedule(); s != "" && k.Type == tea.KeyRight {
+			m.bodyIn[schema.Sched].SetValue(s)
+			return m, nil
+		}
+
 		// pass key to focused input
 		switch m.mode {
 		case modeHeader:
-			m.headIn[m.currCol], _ = m.headIn[m.currCol].Update(k)
+			in := m.headerInputs()[m.currCol]
+			*in, _ = in.Update(k)
 		case modeBody:
 			m.bodyIn[m.currCol], _ = m.bodyIn[m.currCol].Update(k)
 		case modeFooter:
@@ -210,18 +737,393 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 /* ---------- helpers ---------- */
 
+// gridKeys maps each configurable key set to the {row, col} step of its keys.
+// The empty name is the default set.
+var gridKeys = map[string]map[string][2]int{
+	"": {
+		"alt+up": {-1, 0}, "alt+down": {1, 0}, "alt+left": {0, -1}, "alt+right": {0, 1},
+	},
+	"vi": {
+		"alt+k": {-1, 0}, "alt+j": {1, 0}, "alt+h": {0, -1}, "alt+l": {0, 1},
+	},
+}
+
+// enterActions lists the choices for the Enter key, default first.
+var enterActions = []string{"field", "row", "none"}
+
+// enter applies the configured Enter action and reports whether it consumed
+// the key. "field" advances like Tab, moving on to the next row from the last
+// body column; "row" commits and starts the next body row. Entering by
+// column, both move down the column instead.
+func (m *model) enter() bool {
+	if m.cfg.EnterAction == "none" {
+		return false
+	}
+	if m.mode == modeBody && m.byColumn {
+		m.stepDown(1)
+		return true
+	}
+	switch m.cfg.EnterAction {
+	case "row":
+		if m.mode != modeBody {
+			m.moveCol(1)
+			return true
+		}
+	default:
+		if m.mode != modeBody || m.currCol != m.schema.Columns[len(m.schema.Columns)-1] {
+			m.moveCol(1)
+			return true
+		}
+	}
+	if m.currRow < len(m.rows)-1 {
+		m.currCol = m.firstCol()
+		m.moveRow(1)
+	}
+	return true
+}
+
+// moveRow commits the current body row and moves delta rows, staying in range.
+func (m *model) moveRow(delta int) {
+	next := m.currRow + delta
+	if delta == 0 || next < 0 || next >= len(m.rows) {
+		return
+	}
+	m.commitCurrent()
+	m.currRow = next
+	if delta > 0 {
+		m.fillDown()
+	}
+	m.loadCurrent()
+}
+
+// stepDown moves delta rows in column order, running from the bottom of one
+// column of the page to the top of the next.
+func (m *model) stepDown(delta int) {
+	first := m.pageStart()
+	last := first + parser.RowCount - 1
+	next := m.currRow + delta
+	switch {
+	case next > last:
+		m.moveRow(first - m.currRow)
+		m.moveCol(1)
+	case next < first:
+		m.moveRow(last - m.currRow)
+		m.moveCol(-1)
+	default:
+		m.moveRow(delta)
+	}
+}
+
+// pageStart returns the first row of the page holding the current row.
+func (m *model) pageStart() int {
+	return m.currRow / parser.RowCount * parser.RowCount
+}
+
+// turnPage moves to the same row delta pages on, adding a blank page when
+// moving past the last one.
+func (m *model) turnPage(delta int) {
+	next := m.currRow + delta*parser.RowCount
+	if next < 0 {
+		m.notice = "this is the first page"
+		return
+	}
+	if next >= parser.MaxRows {
+		m.notice = "this is the last page a sheet may hold"
+		return
+	}
+	m.commitCurrent()
+	if next >= len(m.rows) {
+		m.rows = parser.PadRows(append(m.rows, make([]Row, next+1-len(m.rows))...))
+	}
+	m.currRow = next
+	m.loadCurrent()
+}
+
+// moveCol moves focus delta fields, wrapping around the current block. In
+// body mode it steps through the year's form order; currCol stays a logical
+// field index.
+func (m *model) moveCol(delta int) {
+	if delta == 0 {
+		return
+	}
+	if m.mode == modeBody {
+		cols := m.schema.Columns
+		pos := (max(m.schema.Pos(m.currCol), 0) + delta%len(cols) + len(cols)) % len(cols)
+		m.currCol = cols[pos]
+	} else {
+		m.currCol += delta
+		m.wrapCol()
+	}
+	m.setFocus()
+}
+
+// jumpToAddress moves to the nearest earlier row with the same Road / House
+// value as the current one, keeping the column.
+func (m *model) jumpToAddress() {
+	addr := strings.TrimSpace(m.bodyIn[schema.Address].Value())
+	if addr == "" {
+		m.notice = "this row has no address to match"
+		return
+	}
+	for r := m.currRow - 1; r >= 0; r-- {
+		if strings.TrimSpace(m.rows[r].Col[schema.Address]) == addr {
+			m.moveRow(r - m.currRow)
+			return
+		}
+	}
+	m.notice = fmt.Sprintf("no earlier row at %q", addr)
+}
+
+// ditto copies the focused field of the row above into the current row,
+// as an enumerator writing "Do." would, leaving focus where it is.
+func (m *model) ditto() {
+	if m.currRow == 0 {
+		m.notice = "there is no row above to copy from"
+		return
+	}
+	v := m.rows[m.currRow-1].Col[m.currCol]
+	if v == "" {
+		m.notice = fmt.Sprintf("%s is blank in the row above", m.schema.Labels[m.currCol])
+		return
+	}
+	in := &m.bodyIn[m.currCol]
+	in.CharLimit = max(in.CharLimit, utf8.RuneCountInString(v))
+	in.SetValue(v)
+	in.CursorEnd()
+}
+
+// showMarkup renders the focused body cell, as typed so far, the way Ctrl-W
+// would write it.
+func (m *model) showMarkup() {
+	opts := m.exportOptions()
+	if opts.PlaceLinks {
+		opts.HTML.PlaceIndex = filepath.Base(m.base()) + ".places.html"
+	}
+	rows := m.liveRows()
+	m.markup = tpl.CellHTML(rows[m.currRow], m.schema, m.currRow, m.currCol, opts.HTML)
+	if m.markup == "" {
+		m.notice = "a blank cell is written as an empty <td>"
+	}
+}
+
+// swapName turns a name round in the focused Name cell: "Smith, John
+// William" becomes "John William Smith", and without a comma the last word,
+// taken as the surname, moves to the front, so "Smith John" becomes "John
+// Smith". A single word is left alone.
+func (m *model) swapName() {
+	if m.currCol != schema.Name {
+		m.notice = "names are turned round in the " + m.schema.Labels[schema.Name] + " column"
+		return
+	}
+	in := &m.bodyIn[schema.Name]
+	if v := swappedName(in.Value()); v != in.Value() {
+		in.SetValue(v)
+		in.CursorEnd()
+	}
+}
+
+// swappedName is the name swapName makes of v.
+func swappedName(v string) string {
+	if surname, rest, ok := strings.Cut(v, ","); ok {
+		if surname, rest = strings.TrimSpace(surname), strings.TrimSpace(rest); surname != "" && rest != "" {
+			return rest + " " + surname
+		}
+		return v
+	}
+	words := strings.Fields(v)
+	if len(words) < 2 {
+		return v
+	}
+	return strings.Join(append(words[len(words)-1:], words[:len(words)-1]...), " ")
+}
+
+// toggleUncertain marks the focused body cell's reading as in doubt, or
+// clears the mark.
+func (m *model) toggleUncertain() {
+	row := &m.rows[m.currRow]
+	if !row.Uncertain[m.currCol] && strings.TrimSpace(m.bodyIn[m.currCol].Value()) == "" {
+		m.notice = "type what you can read before marking it uncertain"
+		return
+	}
+	m.commitCurrent()
+	m.checkpoint()
+	row.Uncertain[m.currCol] = !row.Uncertain[m.currCol]
+}
+
+// refCycle is the order Ctrl-R steps a cell's markup through.
+var refCycle = []parser.RefKind{parser.RefMark, parser.RefPerson, parser.RefPlace}
+
+// cycleKind moves the focused body cell to the next markup element. Landing
+// back on the column's default clears the override. The id the cell was read
+// with belongs to its old element, so it is dropped for its position's.
+func (m *model) cycleKind() {
+	m.checkpoint()
+	row := &m.rows[m.currRow]
+	cur := row.KindOf(m.schema, m.currCol)
+	next := refCycle[0]
+	for i, k := range refCycle {
+		if k == cur {
+			next = refCycle[(i+1)%len(refCycle)]
+		}
+	}
+	if next == parser.DefaultKind(m.schema, m.currCol) {
+		next = parser.RefDefault
+	}
+	row.Kind[m.currCol], row.Ref[m.currCol] = next, ""
+}
+
+// quit leaves the program, asking first when the sheet has changes that were
+// never saved.
+func (m model) quit() (tea.Model, tea.Cmd) {
+	m.commitCurrent()
+	if !m.saved.sameData(m.snap()) {
+		m.confirmQuit = true
+		return m, nil
+	}
+	return m.exit()
+}
+
+// exit saves the settings and ends the program.
+func (m model) exit() (tea.Model, tea.Cmd) {
+	if m.ephemeral {
+		return m, tea.Quit
+	}
+	if err := config.Save(m.cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "config error: %v\n", err)
+	}
+	return m, tea.Quit
+}
+
+// fillDown copies the address from the row above into the current row when
+// the fill-down setting is on and the row is still blank.
+func (m *model) fillDown() {
+	if !m.cfg.FillDown || m.currRow == 0 || m.rows[m.currRow].Col != [parser.FieldCount]string{} {
+		return
+	}
+	if m.rows[m.currRow-1].Col[schema.Address] == "" {
+		return
+	}
+	m.checkpoint()
+	m.rows[m.currRow].Col[schema.Address] = m.rows[m.currRow-1].Col[schema.Address]
+}
+
+// nextSchedule returns one more than the last numeric schedule number above
+// the current row, or "" when there is none.
+func (m *model) nextSchedule() string {
+	for r := m.currRow - 1; r >= 0; r-- {
+		if n, err := strconv.Atoi(strings.TrimSpace(m.rows[r].Col[schema.Sched])); err == nil {
+			return strconv.Itoa(n + 1)
+		}
+	}
+	return ""
+}
+
+// proposedSchedule returns the schedule number offered for the focused
+// cell: the next one, when the setting is on and the cell is an empty
+// Sched#. It is shown beside the field and only written when taken with →,
+// since the later rows of a household leave the number blank.
+func (m *model) proposedSchedule() string {
+	if m.mode != modeBody || m.currCol != schema.Sched || !m.cfg.AutoSchedule || m.bodyIn[schema.Sched].Value() != "" {
+		return ""
+	}
+	return m.nextSchedule()
+}
+
 func (m *model) switchMode(next editMode) {
 	m.commitCurrent()
-	m.mode, m.currCol = next, 0
+	m.mode = next
+	m.currCol = m.firstCol()
+	m.loadCurrent()
+	if next == modeFooter {
+		m.recomputeTotals()
+	}
+}
+
+// firstCol returns the first field of the current mode: the first column on
+// the year's form in body mode, where a year may lack the schedule number.
+func (m *model) firstCol() int {
+	if m.mode == modeBody {
+		return m.schema.Columns[0]
+	}
+	return 0
+}
+
+// applySchema lays the body inputs out for the selected year.
+func (m *model) applySchema() {
+	m.schema = schema.For(m.year)
+	for i := range m.bodyIn {
+		m.bodyIn[i].Placeholder = m.schema.Labels[i]
+		// Tab moves between fields, so → takes the suggestion
+		words := slices.Concat(m.schema.Vocab[i], m.schema.Suggest[i])
+		m.bodyIn[i].SetSuggestions(words)
+		m.bodyIn[i].ShowSuggestions = len(words) > 0
+		m.bodyIn[i].KeyMap.AcceptSuggestion = key.NewBinding(key.WithKeys("right"))
+	}
+	m.sizeInputs()
+	if m.mode == modeBody && !m.schema.Has(m.currCol) {
+		m.currCol = m.schema.Columns[0]
+		m.setFocus()
+	}
+}
+
+// setYear switches to census year y and moves the year menu cursor to it.
+func (m *model) setYear(y string) {
+	m.year = y
+	m.applySchema()
+	for i, cy := range m.years {
+		if cy == y {
+			m.yearIdx = i
+		}
+	}
+}
+
+// sizeInputs fits the inputs to the editor's share of the terminal so long
+// values scroll inside the field instead of wrapping the screen. Body fields
+// get the width their schema gives them, if there is room.
+func (m *model) sizeInputs() {
+	if m.width == 0 {
+		return
+	}
+	editor := m.width
+	if m.preview && m.width >= minPreviewWidth {
+		editor = m.width * 2 / 5
+	}
+	// room after the longest label, its padding and the input prompt
+	label := 0
+	for _, f := range m.schema.Columns {
+		label = max(label, lipgloss.Width(m.schema.Labels[f]))
+	}
+	room := max(editor-label-6, 4)
+	for i := range m.bodyIn {
+		m.bodyIn[i].Width = min(m.schema.Widths[i], room)
+	}
+	room = max(editor-len("Ecc District")-6, 4)
+	for _, in := range m.headerInputs() {
+		in.Width = min(40, room)
+	}
+	for i := range m.footIn {
+		m.footIn[i].Width = min(6, room)
+	}
+}
+
+// clearBlock empties every header or footer field, depending on the mode.
+func (m *model) clearBlock() {
+	m.commitCurrent()
+	m.checkpoint()
+	switch m.mode {
+	case modeHeader:
+		m.header = [parser.HeadCount]string{}
+	case modeFooter:
+		m.footer = [parser.FootCount]string{}
+	}
 	m.loadCurrent()
 }
 
 func (m *model) wrapCol() {
 	switch m.mode {
 	case modeHeader:
-		m.currCol = (m.currCol + parser.HeadCount) % parser.HeadCount
-	case modeBody:
-		m.currCol = (m.currCol + parser.FieldCount) % parser.FieldCount
+		n := parser.HeadCount + parser.MetaCount
+		m.currCol = (m.currCol + n) % n
 	case modeFooter:
 		m.currCol = (m.currCol + parser.FootCount) % parser.FootCount
 	}
@@ -230,10 +1132,19 @@ func (m *model) wrapCol() {
 /* ============== VIEW ============== */
 
 func (m model) View() string {
+	if !m.confirmQuit {
+		return m.view()
+	}
+	return m.view() + "\n\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(
+		"Unsaved changes — press "+m.keyName("write")+" to save and quit, Ctrl‑Q to discard and quit, any other key to cancel")
+}
+
+// view draws the screen of the current mode.
+func (m model) view() string {
 	if m.mode == modeYearSelect {
 		var b bytes.Buffer
 		b.WriteString(lipgloss.NewStyle().Bold(true).Render("Select census year:\n\n"))
-		for i, y := range censusYears {
+		for i, y := range m.years {
 			cursor := " "
 			if i == m.yearIdx {
 				cursor = ">"
@@ -241,11 +1152,41 @@ func (m model) View() string {
 			b.WriteString(fmt.Sprintf("%s %s\n", cursor, y))
 		}
 		b.WriteString("\n(↑/↓ to choose, Enter to select, Esc to quit)")
+		if m.offerRecovery {
+			b.WriteString("\n\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(
+				m.autosavePath()+" is newer than "+m.out+" — press Y to recover it, any other key to leave it"))
+		}
 		return b.String()
 	}
 
+	if m.mode == modeSettings {
+		return m.viewSettings()
+	}
+	if m.mode == modeReplace {
+		return m.viewReplace()
+	}
+	if m.mode == modeOverview {
+		return m.viewOverview()
+	}
+	if m.mode == modeCalc {
+		return m.viewCalc()
+	}
+	if m.mode == modeList {
+		return m.viewList()
+	}
+	if m.mode == modeMerge {
+		return m.viewMerge()
+	}
+	if m.mode == modeRecent {
+		return m.viewRecent()
+	}
+
 	if m.mode == modePickFile {
-		return lipgloss.NewStyle().Bold(true).Render("Pick a census HTML file (Esc to cancel):\n\n") + m.picker.View()
+		view := lipgloss.NewStyle().Bold(true).Render("Pick a census HTML file (Esc to cancel):\n\n") + m.picker.View()
+		if m.peekInfo != "" {
+			view += "\n" + lipgloss.NewStyle().Faint(true).Render(m.peekInfo)
+		}
+		return view
 	}
 
 	var b bytes.Buffer
@@ -253,66 +1194,264 @@ func (m model) View() string {
 	if year == "" {
 		year = "1861"
 	}
-	title := fmt.Sprintf(
-		"%s Census TUI — %-6s  (Ctrl‑H/B/F • ↑↓ • Tab/Shift‑Tab • Ctrl‑N clear row • Ctrl‑O open • Ctrl‑W write • Esc)",
-		year, modeNames[m.mode],
-	)
-	b.WriteString(lipgloss.NewStyle().Bold(true).Render(title) + "\n\n")
+	saved := m.savedState()
+	title := fmt.Sprintf("%s Census TUI%s — %-6s  %s", year, saved, modeNames[m.mode], m.keyHints())
+	// the key hints wrap badly, so narrow terminals get the bare title
+	if m.width > 0 && lipgloss.Width(title) > m.width {
+		title = fmt.Sprintf("%s Census TUI%s — %s", year, saved, modeNames[m.mode])
+	}
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render(title) + "\n")
+	rows := m.liveRows()
+	b.WriteString(lipgloss.NewStyle().Faint(true).Render(
+		fmt.Sprintf("%d people • %d households", countPeople(rows[:]), countHouseholds(m.schema, rows[:]))) + "\n\n")
+	b.WriteString(m.searchLine())
 
 	lbl := lipgloss.NewStyle().Padding(0, 1)
-	printInputs := func(list []ti.Model) {
-		for _, in := range list {
-			b.WriteString(lbl.Render(in.Placeholder) + in.View() + "\n")
+	// label marks field col of mode when a search landed on it
+	label := func(mode editMode, col int, s string) string {
+		if m.onHit(mode, col) {
+			return hitStyle.Render(s)
+		}
+		return lbl.Render(s)
+	}
+	printInputs := func(list []ti.Model, saved []string, first int) {
+		for i, in := range list {
+			b.WriteString(changedMark(in.Value() != saved[i]) + label(modeHeader, first+i, in.Placeholder) + in.View() + "\n")
 		}
 	}
 
 	switch m.mode {
 	case modeHeader:
-		printInputs(m.headIn[:])
+		var head []string
+		for _, v := range m.saved.header {
+			head = append(head, strings.ReplaceAll(v, "\n", lineBreak))
+		}
+		printInputs(m.headIn[:], head, 0)
+		b.WriteString("\n")
+		var meta []string
+		for _, v := range m.saved.meta.Fields() {
+			meta = append(meta, *v)
+		}
+		printInputs(m.metaIn[:], meta, len(m.headIn))
 	case modeBody:
-		b.WriteString(lipgloss.NewStyle().Italic(true).Render(fmt.Sprintf("(Row %d of 25)\n\n", m.currRow+1)))
-		printInputs(m.bodyIn[:])
+		order := ""
+		if m.byColumn {
+			order = " • by column"
+		}
+		if m.goingTo {
+			b.WriteString(lbl.Render("Go to row:") + m.gotoIn.View() + "\n" +
+				lipgloss.NewStyle().Faint(true).Render("(Enter to go, Esc to stay)") + "\n\n")
+		}
+		b.WriteString(lipgloss.NewStyle().Italic(true).Render(fmt.Sprintf("(Row %d of %d • page %d of %d%s)\n\n",
+			m.currRow+1, len(m.rows), m.currRow/parser.RowCount+1, len(m.rows)/parser.RowCount, order)))
+		issues := parser.Validate(m.schema, rows[:])
+		for _, i := range m.schema.Columns {
+			in, at := m.bodyIn[i], issuesAt(issues, m.currRow, i)
+			if len(parser.Errors(at)) > 0 {
+				in.TextStyle = in.TextStyle.Foreground(lipgloss.Color("9"))
+			}
+			line := changedMark(rows[m.currRow].Col[i] != m.savedRow(m.currRow).Col[i]) + label(modeBody, i, in.Placeholder) + in.View()
+			if k := m.rows[m.currRow].Kind[i]; k != parser.RefDefault {
+				line += lipgloss.NewStyle().Faint(true).Render(" <" + k.String() + ">")
+			}
+			if s := m.proposedSchedule(); i == m.currCol && s != "" {
+				line += lipgloss.NewStyle().Faint(true).Render(" → " + s)
+			}
+			if m.rows[m.currRow].Uncertain[i] {
+				line += lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Italic(true).Render(" ? uncertain")
+			}
+			for _, is := range at {
+				line += issueMark(is)
+			}
+			b.WriteString(line + "\n")
+			if ticks := m.choicesLine(); i == m.currCol && ticks != "" {
+				b.WriteString(strings.Repeat(" ", lipgloss.Width(lbl.Render(in.Placeholder))+1) + ticks + "\n")
+			}
+		}
 	case modeFooter:
-		printInputs(m.footIn[:])
+		for i, in := range m.footIn {
+			line := changedMark(in.Value() != m.saved.footer[i]) + label(modeFooter, i, in.Placeholder) + in.View()
+			if m.cfg.AutoTotals {
+				tag := " (auto)"
+				if m.typed(i) {
+					tag = " (manual)"
+				}
+				line += lipgloss.NewStyle().Faint(true).Render(tag)
+			}
+			b.WriteString(line + "\n")
+		}
 	}
 
+	if m.reviewing && m.mode == modeBody {
+		b.WriteString("\n" + m.reviewLine())
+	}
+	if m.markup != "" {
+		b.WriteString("\n" + lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1).Render(m.markup) +
+			"\n" + lipgloss.NewStyle().Faint(true).Render("any key to close"))
+	}
+	if m.canRestore && m.mode == modeBody {
+		b.WriteString("\n" + lipgloss.NewStyle().Faint(true).Render(m.keyName("restore-row")+" restores the last cleared row here"))
+	}
+	if m.confirmClear {
+		b.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(
+			fmt.Sprintf("Clear all %s fields? %s again to confirm, any other key to cancel", strings.ToLower(modeNames[m.mode]), m.keyName("clear"))))
+	}
+	if m.confirmPaste {
+		b.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(
+			fmt.Sprintf("Replace the whole sheet, header and footer too, with the clipboard? %s again to confirm, any other key to cancel", m.keyName("paste"))))
+	}
+	if m.confirmTotals {
+		b.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(
+			"Computed totals differ from the footer: "+m.totalsDiff()+" — press Y to overwrite, any other key to keep them"))
+	}
+	if m.confirmClone {
+		b.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(
+			"This page has unsaved changes — press Y to start the next page anyway ("+m.keyName("undo")+" brings it back), any other key to stay"))
+	}
+	if m.confirmForce != "" {
+		b.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render(m.blockingView()))
+	}
+	if m.fileYear != "" {
+		b.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(
+			fmt.Sprintf("This file is a %s census but you are editing %s — press Y to switch to %s, any other key to keep %s",
+				m.fileYear, year, m.fileYear, year)))
+	}
 	if m.justWrote {
-		b.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Render("✓ census.html written"))
+		b.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Render("✓ "+m.out+" written"))
+	}
+	if m.notice != "" {
+		b.WriteString("\n" + m.notice)
+	}
+	if line := m.autosaveLine(); line != "" {
+		b.WriteString("\n" + line)
 	}
 	if m.justRead {
-		b.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Render("✓ HTML loaded"))
+		b.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Render("✓ HTML loaded"+m.readNote))
+	}
+	for _, n := range m.readNotes {
+		b.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render("⚠ "+n))
+	}
+	if m.preview && m.width >= minPreviewWidth {
+		return m.withPreview(b.String())
 	}
 	return b.String()
 }
 
+// minPreviewWidth is the narrowest terminal the side preview is shown in.
+const minPreviewWidth = 120
+
+// withPreview puts a dim plain-text rendering of the sheet to the right of
+// the editor view.
+func (m model) withPreview(editor string) string {
+	leftW := m.width * 2 / 5
+	panelW := m.width - leftW - 3
+	c := m.census()
+	c.Rows = m.liveRows()
+	panel := lipgloss.NewStyle().Faint(true).Width(panelW).
+		Render(export.Text(c, panelW/len(m.schema.Columns)-1))
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().Width(leftW).Render(editor), " │ ", panel)
+}
+
 /* ============== PERSISTENCE ============== */
 
 func (m *model) commitCurrent() {
+	before := m.snap()
+	defer func() {
+		if !before.sameData(m.snap()) {
+			m.pushUndo(before)
+		}
+	}()
+
 	switch m.mode {
 	case modeHeader:
 		for i := range m.headIn {
-			m.header[i] = m.headIn[i].Value()
+			m.header[i] = m.headerLines(m.headIn[i].Value())
+		}
+		for i, v := range m.meta.Fields() {
+			*v = m.clean(m.metaIn[i].Value())
 		}
 	case modeBody:
 		for i := range m.bodyIn {
-			m.rows[m.currRow].Col[i] = m.bodyIn[i].Value()
+			m.rows[m.currRow].Col[i] = m.clean(m.bodyIn[i].Value())
 		}
 	case modeFooter:
 		for i := range m.footIn {
-			m.footer[i] = m.footIn[i].Value()
+			if v := m.clean(m.footIn[i].Value()); v != m.footer[i] {
+				m.footer[i], m.footManual[i] = v, v != ""
+			}
+		}
+	}
+}
+
+// changedMark starts the line of a field whose value differs from the one
+// last saved or loaded.
+func changedMark(changed bool) string {
+	if changed {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("14")).Render("●")
+	}
+	return " "
+}
+
+// savedRow returns row r as last saved or loaded, blank when the sheet was
+// shorter than that.
+func (m *model) savedRow(r int) Row {
+	if r < len(m.saved.rows) {
+		return m.saved.rows[r]
+	}
+	return Row{}
+}
+
+// liveRows returns the body rows with the uncommitted body inputs applied.
+func (m *model) liveRows() []Row {
+	rows := slices.Clone(m.rows)
+	if m.mode == modeBody {
+		for i := range m.bodyIn {
+			rows[m.currRow].Col[i] = m.clean(m.bodyIn[i].Value())
 		}
 	}
+	return rows
+}
+
+// clean applies the whitespace setting to a value being committed.
+func (m *model) clean(v string) string {
+	if !m.cfg.NormalizeSpace {
+		return v
+	}
+	return strings.Join(strings.Fields(v), " ")
+}
+
+// lineBreak stands for a line break in a header value while it is edited,
+// since an input holds a single line: "St Mary | Great Dunmow" is two lines.
+const lineBreak = " | "
+
+// headerLines reads a header input back as its value, cleaned line by line.
+func (m *model) headerLines(v string) string {
+	if !strings.Contains(v, "|") {
+		return m.clean(v)
+	}
+	lines := strings.Split(v, "|")
+	for i, l := range lines {
+		lines[i] = m.clean(strings.TrimSpace(l))
+	}
+	return strings.Join(lines, "\n")
 }
 
 func (m *model) loadCurrent() {
 	switch m.mode {
 	case modeHeader:
 		for i := range m.headIn {
-			m.headIn[i].SetValue(m.header[i])
+			m.headIn[i].SetValue(strings.ReplaceAll(m.header[i], "\n", lineBreak))
+		}
+		for i, v := range m.meta.Fields() {
+			m.metaIn[i].SetValue(*v)
 		}
 	case modeBody:
 		for i := range m.bodyIn {
-			m.bodyIn[i].SetValue(m.rows[m.currRow].Col[i])
+			// a longer value read from a file is kept whole
+			v := m.rows[m.currRow].Col[i]
+			m.bodyIn[i].CharLimit = max(schema.Limits[i], utf8.RuneCountInString(v))
+			m.bodyIn[i].SetValue(v)
 		}
 	case modeFooter:
 		for i := range m.footIn {
@@ -322,12 +1461,25 @@ func (m *model) loadCurrent() {
 	m.setFocus()
 }
 
-func (m *model) setFocus() {
+// headerInputs returns the inputs of header mode in navigation order: the
+// boundary fields followed by the page metadata.
+func (m *model) headerInputs() []*ti.Model {
+	ins := make([]*ti.Model, 0, parser.HeadCount+parser.MetaCount)
 	for i := range m.headIn {
+		ins = append(ins, &m.headIn[i])
+	}
+	for i := range m.metaIn {
+		ins = append(ins, &m.metaIn[i])
+	}
+	return ins
+}
+
+func (m *model) setFocus() {
+	for i, in := range m.headerInputs() {
 		if m.mode == modeHeader && i == m.currCol {
-			m.headIn[i].Focus()
+			in.Focus()
 		} else {
-			m.headIn[i].Blur()
+			in.Blur()
 		}
 	}
 	for i := range m.bodyIn {
@@ -346,22 +1498,210 @@ func (m *model) setFocus() {
 	}
 }
 
+// clonePage starts the next page of the same district: the header and page
+// metadata carry over with the page number advanced, the body is blank and
+// the footer is kept only if the setting asks for it. The old page stays on
+// the undo stack.
+func (m *model) clonePage() {
+	m.commitCurrent()
+	m.checkpoint()
+	m.rows = parser.PadRows(nil)
+	if !m.cfg.CloneFooter {
+		m.footer = [parser.FootCount]string{}
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(m.meta.Page)); err == nil {
+		m.meta.Page = strconv.Itoa(n + 1)
+	}
+	m.canRestore = false
+	m.mode, m.currRow, m.currCol = modeBody, 0, m.schema.Columns[0]
+	m.loadCurrent()
+}
+
 /* ============== HTML IO ============== */
 
+// verifyWrite reads back the HTML just written to path when the setting asks
+// for it, and warns about the first value that came back different.
+func (m *model) verifyWrite(path string) {
+	if !m.cfg.VerifyWrites {
+		return
+	}
+	if err := export.Verify(m.census(), path, m.exportOptions()); err != nil {
+		m.notice = lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(
+			fmt.Sprintf("⚠ %s does not read back the same — %v", path, err))
+	}
+}
+
+// exportOptions maps the user's settings onto the exporters' options.
+func (m *model) exportOptions() export.Options {
+	return export.Options{HTML: tpl.Options{CellIDs: m.cfg.CellIDs, MaxCellWidth: m.cfg.MaxCellWidth, Credit: m.cfg.Credit, Separators: m.cfg.Separators, Canonical: m.cfg.Canonical, RowCount: m.cfg.RowCount, Theme: m.cfg.Theme, Template: m.template}, PlaceLinks: m.cfg.PlaceLinks, Relationships: m.cfg.Relationships, TextWidth: m.cfg.TextWidth}
+}
+
+// census bundles the committed data for the exporters.
+func (m *model) census() parser.Census {
+	return parser.Census{Year: m.year, Header: m.header, Meta: m.meta, Rows: parser.TrimPages(m.rows), Footer: m.footer}
+}
+
+// loadError says why path could not be opened, for the notice line.
+func loadError(path string, err error) string {
+	msg := err.Error()
+	switch {
+	case errors.Is(err, parser.ErrNotFound):
+		msg = "file not found"
+	case errors.Is(err, parser.ErrMalformedHTML):
+		msg = "unreadable HTML"
+	case errors.Is(err, parser.ErrMalformedCSV):
+		msg = "unreadable CSV"
+	case errors.Is(err, parser.ErrMalformedJSON):
+		msg = "unreadable JSON"
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render("✗ " + filepath.Base(path) + ": " + msg)
+}
+
+// loadFromHTML opens the census page at path, read as CSV for a .csv file,
+// as JSON for a .json one and as HTML otherwise.
 func (m *model) loadFromHTML(path string) error {
-	h, r, f, err := parser.ParseHTML(path)
+	parse := parser.ParseHTML
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		parse = parser.ParseCSV
+	case ".json":
+		parse = parser.ParseJSON
+	}
+	c, err := parse(path)
 	if err != nil {
 		return err
 	}
-	m.header, m.rows, m.footer = h, r, f
-	m.currRow, m.currCol = 0, 0
-	m.loadCurrent()
+	m.loadCensus(c)
+	m.applyLabels(c)
+	m.saved = m.snap()
+	m.readNotes, m.notesUntil = c.Notes, time.Now().Add(notesFor)
+	if c.Year != "" && c.Year != m.year {
+		m.fileYear = c.Year
+	}
 	return nil
 }
 
+// notesFor is how long the notes on an opened page stay under the form.
+const notesFor = 8 * time.Second
+
+// notesMsg asks for the notes on the opened page to be taken down.
+type notesMsg struct{}
+
+// notesTick schedules taking the notes down, or nothing when there are none.
+func (m *model) notesTick() tea.Cmd {
+	if len(m.readNotes) == 0 {
+		return nil
+	}
+	return m.tick(time.Until(m.notesUntil), func(time.Time) tea.Msg { return notesMsg{} })
+}
+
+// applyLabels shows the file's own wording on the header and footer inputs
+// where it differs from the wording this program writes, and the defaults
+// elsewhere. Footer labels usually cover two totals, so the default name is
+// kept beside them.
+func (m *model) applyLabels(c parser.Census) {
+	for i := range m.headIn {
+		m.headIn[i].Placeholder = headLbl[i]
+		if l := c.HeaderLabels[i]; l != "" && !tpl.SameLabel(l, tpl.HeaderLabels[i]) {
+			m.headIn[i].Placeholder = l
+		}
+	}
+	for i := range m.footIn {
+		m.footIn[i].Placeholder = footLbl[i]
+		if l := c.FooterLabels[i]; l != "" && !tpl.SameLabel(l, tpl.FooterLabels[i/2]) {
+			m.footIn[i].Placeholder = l + " · " + footLbl[i]
+		}
+	}
+}
+
+// loadCensus replaces the model's data with c and reloads the inputs. The
+// replacement is a single undo step.
+func (m *model) loadCensus(c parser.Census) {
+	m.commitCurrent()
+	m.checkpoint()
+	m.header, m.meta, m.rows, m.footer = c.Header, c.Meta, parser.PadRows(slices.Clone(c.Rows)), c.Footer
+	m.typedTotals()
+	m.readNote = ""
+	matched := 0
+	for _, ok := range c.HeaderMatched {
+		if ok {
+			matched++
+		}
+	}
+	if matched < parser.HeadCount {
+		m.readNote = fmt.Sprintf(" (%d of %d header fields matched by label)", matched, parser.HeadCount)
+	}
+	m.currRow = 0
+	m.currCol = m.firstCol()
+	m.loadCurrent()
+}
+
 /* ============== PROGRAM ============== */
 
+// Options are the choices given on the command line.
+type Options struct {
+	Out  string // the page Ctrl-W writes, DefaultOut when empty
+	Year string // the census year to edit, skipping the year menu
+	In   string // a page to open on start
+
+	// Template is a page template file to write HTML with, in place of the
+	// built-in page.
+	Template string
+}
+
 // Start launches the Bubble Tea program using this model.
-func Start() error {
-	return tea.NewProgram(NewModel()).Start()
+func Start(opts Options) error {
+	m := NewModel()
+	if err := m.applyOptions(opts); err != nil {
+		return err
+	}
+	return tea.NewProgram(m).Start()
+}
+
+// StartWith launches the program with c already filled in, in place of
+// opts.In. When c names its year, or opts gives one, the year menu is
+// skipped.
+func StartWith(c parser.Census, opts Options) error {
+	m := NewModel()
+	if opts.Year == "" {
+		opts.Year = c.Year
+	}
+	if err := m.applyOptions(Options{Out: opts.Out, Year: opts.Year, Template: opts.Template}); err != nil {
+		return err
+	}
+	m.loadCensus(c)
+	m.undoStack = nil
+	return tea.NewProgram(m).Start()
+}
+
+// applyOptions sets the model up as the command line asks. A page to open
+// is read in the year it names unless a year was given, and either skips
+// the year menu.
+func (m *model) applyOptions(opts Options) error {
+	m.template = opts.Template
+	if opts.Out != "" {
+		m.out = opts.Out
+		m.offerRecovery = m.newerBackup()
+	}
+	if opts.Year == "" && opts.In == "" {
+		return nil
+	}
+	y := opts.Year
+	if y == "" {
+		y = m.years[m.yearIdx]
+	}
+	m.setYear(y)
+	m.offerRecovery = false
+	if opts.In != "" {
+		if err := m.loadFromHTML(opts.In); err != nil {
+			return err
+		}
+		if m.fileYear != "" && opts.Year == "" {
+			m.setYear(m.fileYear)
+			m.fileYear = ""
+		}
+		m.remember(opts.In)
+	}
+	m.switchMode(modeHeader)
+	return nil
 }
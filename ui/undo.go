@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"slices"
+
+	"testme/parser"
+)
+
+// snapshot is the committed sheet plus the focus at the time it was taken.
+// Every undo entry is a whole snapshot, so an operation touching many cells
+// (clearing a row, pasting, loading) undoes in one step. The same holds for
+// anything that moves rows about: one checkpoint before the first move
+// brings the old order back with a single undo.
+type snapshot struct {
+	header [parser.HeadCount]string
+	meta   parser.Meta
+	rows   []Row
+	footer [parser.FootCount]string
+	manual [parser.FootCount]bool // footManual, the totals typed by hand
+
+	mode             editMode
+	currRow, currCol int
+}
+
+func (m *model) snap() snapshot {
+	return snapshot{m.header, m.meta, slices.Clone(m.rows), m.footer, m.footManual, m.mode, m.currRow, m.currCol}
+}
+
+// liveSnap is snap with the inputs being typed into applied, as
+// commitCurrent would store them.
+func (m *model) liveSnap() snapshot {
+	s := m.snap()
+	s.rows = m.liveRows()
+	switch m.mode {
+	case modeHeader:
+		for i := range m.headIn {
+			s.header[i] = m.headerLines(m.headIn[i].Value())
+		}
+		for i, v := range s.meta.Fields() {
+			*v = m.clean(m.metaIn[i].Value())
+		}
+	case modeFooter:
+		for i := range m.footIn {
+			s.footer[i] = m.clean(m.footIn[i].Value())
+		}
+	}
+	return s
+}
+
+// sameData compares the sheets of two snapshots. Blank pages after the last
+// filled one do not count, so paging past the end is not an edit.
+func (s snapshot) sameData(o snapshot) bool {
+	return s.header == o.header && s.meta == o.meta && s.footer == o.footer &&
+		slices.Equal(parser.TrimPages(s.rows), parser.TrimPages(o.rows))
+}
+
+// checkpoint records the current state as one undoable step and drops any
+// redo history. Call it before changing several cells as a group.
+func (m *model) checkpoint() {
+	m.pushUndo(m.snap())
+}
+
+// undoLimit is how many steps undo keeps; older ones are dropped.
+const undoLimit = 100
+
+func (m *model) pushUndo(s snapshot) {
+	if len(m.undoStack) >= undoLimit {
+		m.undoStack = slices.Delete(m.undoStack, 0, len(m.undoStack)-undoLimit+1)
+	}
+	m.undoStack = append(m.undoStack, s)
+	m.redoStack = nil
+}
+
+// undo commits pending input, then restores the state before the last step.
+func (m *model) undo() bool {
+	m.commitCurrent()
+	if len(m.undoStack) == 0 {
+		return false
+	}
+	prev := m.undoStack[len(m.undoStack)-1]
+	m.undoStack = m.undoStack[:len(m.undoStack)-1]
+	m.redoStack = append(m.redoStack, m.snap())
+	m.restore(prev)
+	return true
+}
+
+// redo reapplies the step most recently undone.
+func (m *model) redo() bool {
+	m.commitCurrent()
+	if len(m.redoStack) == 0 {
+		return false
+	}
+	next := m.redoStack[len(m.redoStack)-1]
+	m.redoStack = m.redoStack[:len(m.redoStack)-1]
+	m.undoStack = append(m.undoStack, m.snap())
+	m.restore(next)
+	return true
+}
+
+// restore puts s back and moves focus to where that state was edited.
+func (m *model) restore(s snapshot) {
+	m.header, m.meta, m.rows, m.footer = s.header, s.meta, slices.Clone(s.rows), s.footer
+	m.footManual = s.manual
+	m.mode, m.currRow, m.currCol = s.mode, s.currRow, s.currCol
+	m.loadCurrent()
+}
@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"testme/schema"
+)
+
+// scheds returns the Sched# of the first n rows of the sheet.
+func scheds(h *Harness, n int) []string {
+	var out []string
+	for _, r := range h.Census().Rows[:n] {
+		out = append(out, r.Col[schema.Sched])
+	}
+	return out
+}
+
+// threeRows types schedules 1, 2 and 3 down the first body rows and leaves
+// the focus on the second.
+func threeRows(t *testing.T) *Harness {
+	t.Helper()
+	h := bodyRow(t, "1")
+	h.Key(tea.KeyDown)
+	h.Type("2")
+	h.Key(tea.KeyDown)
+	h.Type("3")
+	h.Key(tea.KeyUp)
+	return h
+}
+
+func TestUndoRowMoves(t *testing.T) {
+	want := []string{"1", "2", "3", ""}
+	for _, tc := range []struct {
+		name  string
+		key   tea.KeyType
+		moved []string
+	}{
+		{"insert", tea.KeyInsert, []string{"1", "", "2", "3"}},
+		{"delete", tea.KeyCtrlD, []string{"1", "3", "", ""}},
+	} {
+		h := threeRows(t)
+		h.Key(tc.key)
+		if got := scheds(h, 4); !slices.Equal(got, tc.moved) {
+			t.Fatalf("%s: rows %q, want %q", tc.name, got, tc.moved)
+		}
+		h.Key(tea.KeyCtrlZ)
+		if got := scheds(h, 4); !slices.Equal(got, want) {
+			t.Errorf("%s: one Ctrl-Z left rows %q, want %q", tc.name, got, want)
+		}
+		h.Key(tea.KeyCtrlY)
+		if got := scheds(h, 4); !slices.Equal(got, tc.moved) {
+			t.Errorf("%s: Ctrl-Y left rows %q, want %q", tc.name, got, tc.moved)
+		}
+	}
+}
+
+func TestUndoWholeRowInOneStep(t *testing.T) {
+	full := []string{"1", "High Street", "1", "", "John Smith", "Head"}
+	for _, tc := range []struct {
+		name string
+		edit func(h *Harness)
+		row  int
+	}{
+		{"clear", func(h *Harness) { h.Key(tea.KeyCtrlN) }, 0},
+		{"paste", func(h *Harness) { h.Alt('y'); h.Key(tea.KeyDown); h.Alt('b') }, 1},
+	} {
+		h := bodyRow(t, full...)
+		h.Key(tea.KeyDown, tea.KeyUp)
+		before := h.Census().Rows[tc.row]
+		tc.edit(h)
+		if h.Census().Rows[tc.row] == before {
+			t.Fatalf("%s: row %d unchanged", tc.name, tc.row+1)
+		}
+		h.Key(tea.KeyCtrlZ)
+		if got := h.Census().Rows[tc.row]; got != before {
+			t.Errorf("%s: one Ctrl-Z left row %d as %q, want %q", tc.name, tc.row+1, got.Col, before.Col)
+		}
+	}
+}
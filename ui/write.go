@@ -0,0 +1,132 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"testme/export"
+	"testme/parser"
+	"testme/schema"
+)
+
+// DefaultOut is the page Ctrl-W writes unless told otherwise.
+const DefaultOut = "census.html"
+
+// base is the page Ctrl-W writes without its extension, the name every
+// format is written under.
+func (m *model) base() string { return strings.TrimSuffix(m.out, filepath.Ext(m.out)) }
+
+// write saves the sheet as the page m.out, with the places index beside it when
+// birthplaces are linked.
+func (m *model) write() {
+	names := []string{"html"}
+	if m.cfg.PlaceLinks {
+		names = append(names, "places")
+	}
+	for _, r := range export.WriteAll(m.census(), m.base(), names, m.exportOptions()) {
+		switch {
+		case r.Err != nil:
+			fmt.Fprintf(os.Stderr, "save error: %v\n", r.Err)
+		case r.Format == "html":
+			m.justWrote, m.saved, m.lastSaved = true, m.snap(), time.Now()
+			m.remember(r.Path)
+			m.verifyWrite(r.Path)
+		}
+	}
+}
+
+// exportAll writes the sheet in every format and lists the outcome.
+func (m *model) exportAll() {
+	var lines []string
+	for _, r := range export.WriteAll(m.census(), m.base(), nil, m.exportOptions()) {
+		lines = append(lines, r.String())
+		if r.Format == "html" && r.Err == nil {
+			m.saved, m.lastSaved = m.snap(), time.Now()
+			m.remember(r.Path)
+			m.verifyWrite(r.Path)
+		}
+	}
+	m.notice = strings.TrimSpace(strings.Join(lines, "  ") + "\n" + m.notice)
+}
+
+// exportCSV writes the body beside the page as CSV for spreadsheets, so
+// census.csv for census.html.
+func (m *model) exportCSV() {
+	for _, r := range export.WriteAll(m.census(), m.base(), []string{"csv"}, m.exportOptions()) {
+		m.notice = r.String()
+	}
+}
+
+// exportTXT writes the body beside the page as a fixed-width text listing
+// for printing, census.txt for census.html.
+func (m *model) exportTXT() {
+	for _, r := range export.WriteAll(m.census(), m.base(), []string{"txt"}, m.exportOptions()) {
+		m.notice = r.String()
+	}
+}
+
+// saveJSON saves the sheet beside the page as JSON, census.json for
+// census.html, which reads back exactly and so counts as saved.
+func (m *model) saveJSON() {
+	for _, r := range export.WriteAll(m.census(), m.base(), []string{"json"}, m.exportOptions()) {
+		m.notice = r.String()
+		if r.Err == nil {
+			m.saved, m.lastSaved = m.snap(), time.Now()
+			m.remember(r.Path)
+		}
+	}
+}
+
+// dirty reports whether the sheet, as typed so far, differs from the page
+// last saved or loaded.
+func (m *model) dirty() bool { return !m.saved.sameData(m.liveSnap()) }
+
+// savedState is the title bar's note on saving: a star while the sheet has
+// unsaved changes, and the time of the last save once it has none.
+func (m *model) savedState() string {
+	switch {
+	case m.dirty():
+		return " *"
+	case !m.lastSaved.IsZero():
+		return " (saved " + m.lastSaved.Format("15:04") + ")"
+	}
+	return ""
+}
+
+// blocked reports whether errors in the body stop action: ages that cannot
+// be read always do, and every other error under the strict setting. The
+// errors are listed under the form, and pressing the action's key once more
+// writes anyway.
+func (m *model) blocked(action string) bool {
+	errs := parser.Errors(parser.Validate(m.schema, m.rows[:]))
+	if !m.cfg.Strict {
+		errs = slices.DeleteFunc(errs, func(is parser.Issue) bool {
+			return is.Col != schema.AgeMale && is.Col != schema.AgeFemale
+		})
+	}
+	if len(errs) == 0 {
+		return false
+	}
+	m.blocking, m.confirmForce = errs, action
+	return true
+}
+
+// blockingView lists the errors that stopped a write.
+func (m *model) blockingView() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Not written: %d error(s) on the sheet", len(m.blocking))
+	for i, is := range m.blocking {
+		if i == maxPreview {
+			fmt.Fprintf(&b, "\n  … and %d more", len(m.blocking)-maxPreview)
+			break
+		}
+		b.WriteString("\n  " + is.Describe(m.schema))
+	}
+	fmt.Fprintf(&b, "\n%s again writes anyway, any other key cancels (%s steps through them)",
+		m.keyName(m.confirmForce), m.keyName("review"))
+	return b.String()
+}
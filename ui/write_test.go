@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestBadAgeBlocksWrite(t *testing.T) {
+	h := bodyRow(t, "1", "", "", "", "John Smith", "Head", "Mar", "forty")
+	h.Key(tea.KeyCtrlW)
+	if _, err := os.Stat("census.html"); err == nil {
+		t.Fatal("Ctrl-W wrote a sheet with an unreadable age")
+	}
+	if !strings.Contains(h.View(), "not an age") {
+		t.Errorf("the age error is not listed:\n%s", h.View())
+	}
+	h.Key(tea.KeyCtrlW)
+	if _, err := os.Stat("census.html"); err != nil {
+		t.Errorf("Ctrl-W again did not force the write: %v", err)
+	}
+}
+
+func TestOtherErrorsBlockOnlyWhenStrict(t *testing.T) {
+	// schedule 1 comes back after another household's row
+	h := bodyRow(t, "1")
+	h.Key(tea.KeyDown)
+	h.Type("2")
+	h.Key(tea.KeyDown)
+	h.Type("1")
+	h.Key(tea.KeyCtrlW)
+	if _, err := os.Stat("census.html"); err != nil {
+		t.Fatalf("a repeated schedule number blocked the write without the strict setting: %v", err)
+	}
+	os.Remove("census.html")
+
+	h.Config().Strict = true
+	h.Key(tea.KeyCtrlW)
+	if _, err := os.Stat("census.html"); err == nil {
+		t.Error("the strict setting let a repeated schedule number be written")
+	}
+}